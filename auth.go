@@ -0,0 +1,124 @@
+package bigModel
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuthMode 请求的鉴权方式.
+type AuthMode int
+
+const (
+	// AuthModeAPIKey 直接使用 APIKEY 作为 Bearer 令牌，默认方式
+	AuthModeAPIKey AuthMode = iota
+	// AuthModeJWT 使用 "id.secret" 格式的 APIKEY 在本地签发 HS256 JWT 作为 Bearer 令牌，密钥不会在网络上传输
+	AuthModeJWT
+)
+
+// DefaultJWTTTL JWT令牌默认的有效期
+const DefaultJWTTTL = 30 * time.Minute
+
+// jwtRefreshDivisor 令牌剩余有效期低于 ttl/jwtRefreshDivisor（即有效期的五分之一）时提前刷新
+const jwtRefreshDivisor = 5
+
+// WithAuthMode 设置API客户端的鉴权方式.
+func WithAuthMode(mode AuthMode) Option {
+	return func(c *Client) error {
+		switch mode {
+		case AuthModeAPIKey:
+		case AuthModeJWT:
+			if _, _, err := splitAPIKey(c.AuthToken); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unknown auth mode %d", mode)
+		}
+		c.AuthMode = mode
+		return nil
+	}
+}
+
+// WithJWTTTL 设置JWT令牌的有效期，仅在 AuthModeJWT 下生效.
+func WithJWTTTL(ttl time.Duration) Option {
+	return func(c *Client) error {
+		if ttl <= 0 {
+			return errors.New("jwt ttl must be a positive duration")
+		}
+		c.JWTTTL = ttl
+		return nil
+	}
+}
+
+// jwtCache 缓存已签发的JWT令牌，在过期前自动刷新.
+type jwtCache struct {
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// authorization 返回本次请求使用的 Authorization 请求头.
+func (c *Client) authorization() (string, error) {
+	if c.AuthMode != AuthModeJWT {
+		return "Bearer " + c.AuthToken, nil
+	}
+	ttl := c.JWTTTL
+	if ttl <= 0 {
+		ttl = DefaultJWTTTL
+	}
+	c.jwt.mu.Lock()
+	defer c.jwt.mu.Unlock()
+	now := time.Now()
+	if c.jwt.token == "" || c.jwt.expiresAt.Sub(now) < ttl/jwtRefreshDivisor {
+		token, err := signJWT(c.AuthToken, now, ttl)
+		if err != nil {
+			return "", err
+		}
+		c.jwt.token = token
+		c.jwt.expiresAt = now.Add(ttl)
+	}
+	return "Bearer " + c.jwt.token, nil
+}
+
+// splitAPIKey 将 "id.secret" 格式的 APIKEY 拆分为 id 和 secret.
+func splitAPIKey(apiKey string) (id string, secret string, err error) {
+	id, secret, ok := strings.Cut(apiKey, ".")
+	if !ok || id == "" || secret == "" || strings.Contains(secret, ".") {
+		return "", "", errors.New("APIKEY 格式错误，JWT 鉴权需要 id.secret 格式的 APIKEY")
+	}
+	return id, secret, nil
+}
+
+// signJWT 使用 APIKEY 中的 secret 签发 HS256 JWT.
+func signJWT(apiKey string, now time.Time, ttl time.Duration) (string, error) {
+	id, secret, err := splitAPIKey(apiKey)
+	if err != nil {
+		return "", err
+	}
+	header, err := json.Marshal(map[string]string{
+		"alg":       "HS256",
+		"sign_type": "SIGN",
+	})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"api_key":   id,
+		"exp":       now.Add(ttl).UnixMilli(),
+		"timestamp": now.UnixMilli(),
+	})
+	if err != nil {
+		return "", err
+	}
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(payload)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(unsigned))
+	return unsigned + "." + encoding.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,109 @@
+package bigModel
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// decodeJWT 拆分令牌并解码 header 和 payload.
+func decodeJWT(t *testing.T, token string) (header map[string]any, payload map[string]any, parts []string) {
+	t.Helper()
+	parts = strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("token %q has %d parts, want 3", token, len(parts))
+	}
+	for i, v := range []*map[string]any{&header, &payload} {
+		data, err := base64.RawURLEncoding.DecodeString(parts[i])
+		if err != nil {
+			t.Fatalf("decode part %d: %v", i, err)
+		}
+		if err := json.Unmarshal(data, v); err != nil {
+			t.Fatalf("unmarshal part %d: %v", i, err)
+		}
+	}
+	return header, payload, parts
+}
+
+func TestSignJWT(t *testing.T) {
+	now := time.UnixMilli(1700000000123)
+	token, err := signJWT("my-id.my-secret", now, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header, payload, parts := decodeJWT(t, token)
+	if header["alg"] != "HS256" || header["sign_type"] != "SIGN" {
+		t.Fatalf("header = %v", header)
+	}
+	if payload["api_key"] != "my-id" {
+		t.Fatalf("api_key = %v, want my-id", payload["api_key"])
+	}
+	// 智谱要求 exp 和 timestamp 为毫秒时间戳
+	if got := int64(payload["timestamp"].(float64)); got != now.UnixMilli() {
+		t.Fatalf("timestamp = %d, want %d", got, now.UnixMilli())
+	}
+	if got := int64(payload["exp"].(float64)); got != now.Add(10*time.Minute).UnixMilli() {
+		t.Fatalf("exp = %d, want %d", got, now.Add(10*time.Minute).UnixMilli())
+	}
+	mac := hmac.New(sha256.New, []byte("my-secret"))
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if want := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)); parts[2] != want {
+		t.Fatalf("signature = %q, want %q", parts[2], want)
+	}
+}
+
+func TestAuthorizationJWTCache(t *testing.T) {
+	ttl := 10 * time.Minute
+	c, err := NewClientWithOptions("my-id.my-secret", WithAuthMode(AuthModeJWT), WithJWTTTL(ttl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := c.authorization()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(first, "Bearer ") {
+		t.Fatalf("authorization = %q, want Bearer token", first)
+	}
+	time.Sleep(2 * time.Millisecond)
+	second, _ := c.authorization()
+	if second != first {
+		t.Fatal("cached token was not reused")
+	}
+	// 剩余有效期仍高于 ttl/5 时继续使用缓存
+	c.jwt.expiresAt = time.Now().Add(ttl/jwtRefreshDivisor + time.Minute)
+	if third, _ := c.authorization(); third != first {
+		t.Fatal("token refreshed before ttl/5 remained")
+	}
+	// 剩余有效期低于 ttl/5 时重新签发
+	c.jwt.expiresAt = time.Now().Add(ttl/jwtRefreshDivisor - time.Second)
+	refreshed, _ := c.authorization()
+	if refreshed == first {
+		t.Fatal("token was not re-signed near expiry")
+	}
+	if c.jwt.expiresAt.Sub(time.Now()) < ttl-time.Second {
+		t.Fatalf("expiresAt not extended: %v", c.jwt.expiresAt)
+	}
+}
+
+func TestAuthorizationAPIKey(t *testing.T) {
+	c, err := NewClientWithOptions("plain-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := c.authorization(); got != "Bearer plain-key" {
+		t.Fatalf("authorization = %q", got)
+	}
+}
+
+func TestWithAuthModeRejectsInvalidKey(t *testing.T) {
+	for _, key := range []string{"no-dot", ".secret", "id.", "id.sec.ret"} {
+		if _, err := NewClientWithOptions(key, WithAuthMode(AuthModeJWT)); err == nil {
+			t.Fatalf("key %q: expected error", key)
+		}
+	}
+}
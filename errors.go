@@ -1,84 +1,318 @@
 package bigModel
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
+)
+
+// 错误分类，可以通过 errors.Is(err, bigModel.ErrRateLimited) 判断失败类型.
+var (
+	// ErrAuth 鉴权失败，APIKEY无效、过期或无权访问.
+	ErrAuth = errors.New("鉴权失败")
+	// ErrInsufficientBalance 账户余额不足或已欠费.
+	ErrInsufficientBalance = errors.New("账户余额不足")
+	// ErrRateLimited 请求频率、并发数或调用量超出限制.
+	ErrRateLimited = errors.New("请求超出频率限制")
+	// ErrContentFiltered 输入或输出内容触发内容安全策略.
+	ErrContentFiltered = errors.New("内容触发安全策略")
+	// ErrModelNotFound 模型或接口不存在.
+	ErrModelNotFound = errors.New("模型或接口不存在")
+	// ErrInvalidRequest 请求参数有误.
+	ErrInvalidRequest = errors.New("请求参数有误")
+	// ErrServerError 服务端处理错误.
+	ErrServerError = errors.New("服务端错误")
+	// ErrTimeout 请求超时.
+	ErrTimeout = errors.New("请求超时")
+	// ErrStreamInterrupted 流式响应在结束前中断.
+	ErrStreamInterrupted = errors.New("流式响应中断")
 )
 
 // APIError represents an error returned by the API.
 type APIError struct {
-	StatusCode    int    // HTTP status code
-	APICode       int    // Business error code from API response
-	Message       string // Human-readable error message
-	OriginalError error  // Wrapped error for debugging
-	ResponseBody  string // Raw JSON response body
+	StatusCode    int           // HTTP status code
+	APICode       int           // Business error code from API response
+	Message       string        // Human-readable error message
+	Kind          error         // 错误分类，取值为 ErrAuth 等错误分类之一
+	RequestId     string        // 请求 ID，便于向平台反馈问题
+	RetryAfter    time.Duration // 服务端建议的重试等待时间，来自 Retry-After 响应头
+	OriginalError error         // Wrapped error for debugging
+	ResponseBody  string        // Raw JSON response body
 }
 
 // Error returns a string representation of the error.
 func (e APIError) Error() string {
+	if e.APICode != 0 && e.StatusCode == 0 {
+		return fmt.Sprintf("Code %d: %s", e.APICode, e.Message)
+	}
 	if e.APICode != 0 {
 		return fmt.Sprintf("HTTP %d (Code %d): %s", e.StatusCode, e.APICode, e.Message)
 	}
+	if e.StatusCode == 0 {
+		return e.Message
+	}
 	return fmt.Sprintf("HTTP %d: %s \n%v", e.StatusCode, e.Message, e.ResponseBody)
 }
 
-// HandleError handles an error response from the API.
-func HandleError(resp *http.Response) error {
-	defer func() { _ = resp.Body.Close() }()
+// Is 判断错误是否属于指定的错误分类.
+func (e APIError) Is(target error) bool {
+	return e.Kind != nil && e.Kind == target
+}
 
-	body, _ := io.ReadAll(resp.Body)
-	responseBody := string(body)
+// Unwrap 返回被包装的原始错误.
+func (e APIError) Unwrap() error {
+	return e.OriginalError
+}
 
-	// Check if the response is HTML
-	if strings.HasPrefix(responseBody, "<html>") {
-		return &APIError{
-			StatusCode: resp.StatusCode,
-			Message:    "Unexpected HTML response (model may not exist). This is likely an issue with the how some external servers return html responses for error.",
-		}
+// apiCodeKinds 平台业务错误码与错误分类的对应关系.
+var apiCodeKinds = map[int]error{
+	1000: ErrAuth,                // 身份验证失败
+	1001: ErrAuth,                // Header中未收到Authentication参数
+	1002: ErrAuth,                // Authentication Token非法
+	1003: ErrAuth,                // Authentication Token已过期
+	1004: ErrAuth,                // 提供的Authentication Token验证失败
+	1110: ErrAuth,                // 账户处于非活动状态
+	1111: ErrAuth,                // 账户不存在
+	1112: ErrAuth,                // 账户已被锁定
+	1113: ErrInsufficientBalance, // 账户已欠费
+	1120: ErrAuth,                // 无法成功访问账户
+	1210: ErrInvalidRequest,      // API 调用参数有误
+	1211: ErrModelNotFound,       // 模型不存在
+	1212: ErrInvalidRequest,      // 当前模型不支持该调用方式
+	1213: ErrInvalidRequest,      // 未正常接收到参数
+	1214: ErrInvalidRequest,      // 参数非法
+	1215: ErrInvalidRequest,      // 参数不能同时设置
+	1220: ErrAuth,                // 无权访问该API
+	1221: ErrModelNotFound,       // API已下线
+	1222: ErrModelNotFound,       // API不存在
+	1230: ErrServerError,         // API调用流程出错
+	1231: ErrInvalidRequest,      // 已有请求
+	1234: ErrServerError,         // 网络错误
+	1261: ErrInvalidRequest,      // Prompt 超长
+	1300: ErrContentFiltered,     // 调用被策略阻止
+	1301: ErrContentFiltered,     // 系统检测到输入或生成内容可能包含不安全或敏感内容
+	1302: ErrRateLimited,         // 并发数过高
+	1303: ErrRateLimited,         // 频率过高
+	1304: ErrRateLimited,         // 调用次数超过当日限额
+	1305: ErrRateLimited,         // 已触发流量限制
+	1308: ErrRateLimited,         // 使用量已达上限
+	1309: ErrInsufficientBalance, // 套餐已到期
+	1310: ErrRateLimited,         // 周期使用量已达上限
+}
+
+// kindFromAPICode 根据业务错误码返回错误分类.
+func kindFromAPICode(code int) error {
+	if kind, ok := apiCodeKinds[code]; ok {
+		return kind
 	}
+	switch {
+	case code >= 1000 && code < 1100:
+		return ErrAuth
+	case code >= 1200 && code < 1300:
+		return ErrInvalidRequest
+	case code >= 1300 && code < 1400:
+		return ErrRateLimited
+	}
+	return nil
+}
+
+// kindFromStatus 根据HTTP状态码返回错误分类.
+func kindFromStatus(statusCode int) error {
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrAuth
+	case statusCode == http.StatusPaymentRequired:
+		return ErrInsufficientBalance
+	case statusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case statusCode == http.StatusNotFound:
+		return ErrModelNotFound
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		return ErrTimeout
+	case statusCode >= 500:
+		return ErrServerError
+	case statusCode >= 400:
+		return ErrInvalidRequest
+	}
+	return nil
+}
 
-	// why are we even defining a new struct here? // We can just use the APIError struct above. // Because we want to parse the JSON response and extract the error message. // We can use the APIError struct above to do that.
+// apiErrorBody 平台返回的错误响应，兼容 {"error":{"code":"1211","message":""}} 和 {"code":1211,"message":""} 两种格式.
+type apiErrorBody struct {
+	Error *struct {
+		Code    json.RawMessage `json:"code"`
+		Message string          `json:"message"`
+	} `json:"error"`
+	Code      json.RawMessage `json:"code"`
+	Message   string          `json:"message"`
+	Msg       string          `json:"msg"`
+	RequestId string          `json:"request_id"`
+	ID        string          `json:"id"`
+}
 
-	var apiResponse struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
+// parseAPICode 解析字符串或数字形式的业务错误码.
+func parseAPICode(raw json.RawMessage) int {
+	value := strings.Trim(string(raw), `"`)
+	code, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
 	}
+	return code
+}
 
-	err := json.Unmarshal(body, &apiResponse)
+// parseAPIErrorBody 解析错误响应正文，返回业务错误码、错误信息和请求 ID.
+func parseAPIErrorBody(body []byte) (code int, message string, requestId string, err error) {
+	var parsed apiErrorBody
+	if err = json.Unmarshal(body, &parsed); err != nil {
+		return 0, "", "", err
+	}
+	requestId = parsed.RequestId
+	if requestId == "" {
+		requestId = parsed.ID
+	}
+	if parsed.Error != nil {
+		return parseAPICode(parsed.Error.Code), parsed.Error.Message, requestId, nil
+	}
+	message = parsed.Message
+	if message == "" {
+		message = parsed.Msg
+	}
+	return parseAPICode(parsed.Code), message, requestId, nil
+}
 
-	baseError := &APIError{
-		StatusCode:   resp.StatusCode,
-		APICode:      apiResponse.Code,
-		Message:      apiResponse.Message,
+// NewAPIError 根据HTTP状态码、响应头和响应正文构造 APIError.
+func NewAPIError(statusCode int, header http.Header, body []byte) *APIError {
+	responseBody := string(body)
+	apiErr := &APIError{
+		StatusCode:   statusCode,
 		ResponseBody: responseBody,
+		Kind:         kindFromStatus(statusCode),
+	}
+	if header != nil {
+		apiErr.RequestId = header.Get("X-Request-Id")
+		if d, ok := parseRetryAfter(header.Get("Retry-After")); ok {
+			apiErr.RetryAfter = d
+		}
 	}
 
-	if err == nil && apiResponse.Code != 0 {
-		return baseError
+	// Check if the response is HTML
+	trimmed := strings.TrimSpace(responseBody)
+	if isHTML(trimmed) {
+		apiErr.Message = "Unexpected HTML response (model may not exist). This is likely an issue with the how some external servers return html responses for error."
+		apiErr.Kind = ErrModelNotFound
+		return apiErr
+	}
+	if len(trimmed) == 0 {
+		apiErr.Message = "解析响应JSON失败：响应正文为空"
+		if apiErr.Kind == nil {
+			apiErr.Kind = ErrServerError
+		}
+		return apiErr
+	}
+
+	code, message, requestId, err := parseAPIErrorBody(body)
+	if requestId != "" && apiErr.RequestId == "" {
+		apiErr.RequestId = requestId
+	}
+	if err == nil && (code != 0 || message != "") {
+		apiErr.APICode = code
+		apiErr.Message = message
+		if kind := kindFromAPICode(code); kind != nil {
+			apiErr.Kind = kind
+		}
+		if apiErr.Kind == nil {
+			apiErr.Kind = ErrServerError
+		}
+		return apiErr
 	}
 
 	// Handle cases where the error response couldn't be parsed
-	baseError.ResponseBody = responseBody
-	switch resp.StatusCode {
+	switch statusCode {
 	case http.StatusBadRequest:
-		baseError.Message = "Bad request"
+		apiErr.Message = "Bad request"
 	case http.StatusUnauthorized:
-		baseError.Message = "Invalid authentication credentials"
+		apiErr.Message = "Invalid authentication credentials"
 	case http.StatusPaymentRequired:
-		baseError.Message = "Insufficient account balance"
+		apiErr.Message = "Insufficient account balance"
 	case http.StatusTooManyRequests:
-		baseError.Message = "Rate limit exceeded"
+		apiErr.Message = "Rate limit exceeded"
 	case http.StatusNotFound:
-		baseError.Message = "Requested resource not found"
+		apiErr.Message = "Requested resource not found"
 	case http.StatusInternalServerError:
-		baseError.Message = "Internal server error"
+		apiErr.Message = "Internal server error"
 	default:
-		baseError.Message = fmt.Sprintf("Unexpected API response (HTTP %d)", resp.StatusCode)
+		apiErr.Message = fmt.Sprintf("Unexpected API response (HTTP %d)", statusCode)
+	}
+	if apiErr.Kind == nil {
+		apiErr.Kind = ErrServerError
 	}
+	if err != nil {
+		apiErr.OriginalError = fmt.Errorf("failed to decode: %w (body: %s)", err, responseBody)
+	}
+	return apiErr
+}
+
+// HandleError handles an error response from the API.
+func HandleError(resp *http.Response) error {
+	defer func() { _ = resp.Body.Close() }()
 
-	baseError.OriginalError = fmt.Errorf("failed to decode: %w (body: %s)", err, responseBody)
-	return baseError
+	body, _ := io.ReadAll(resp.Body)
+	return NewAPIError(resp.StatusCode, resp.Header, body)
+}
+
+// HandleAPIError 通过解析响应主体来处理API错误.
+// 用于HTTP状态码正常但响应正文无法解析为预期结构的情况.
+func HandleAPIError(body []byte) error {
+	trimmed := strings.TrimSpace(string(body))
+	if len(trimmed) == 0 || isHTML(trimmed) {
+		return NewAPIError(http.StatusOK, nil, body)
+	}
+	if code, message, _, err := parseAPIErrorBody(body); err == nil && (code != 0 || message != "") {
+		return NewAPIError(http.StatusOK, nil, body)
+	}
+	return &APIError{
+		StatusCode:   http.StatusOK,
+		Message:      "无法解析响应JSON：JSON输入意外结束.",
+		Kind:         ErrServerError,
+		ResponseBody: string(body),
+	}
+}
+
+// isHTML 判断响应正文是否为HTML页面.
+func isHTML(body string) bool {
+	return strings.HasPrefix(body, "<html>") || strings.HasPrefix(body, "<!DOCTYPE html>")
+}
+
+// NewTransportError 将发送请求时的网络错误转换为错误，超时类错误归类为 ErrTimeout.
+func NewTransportError(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &APIError{
+			Message:       fmt.Sprintf("请求超时: %v", err),
+			Kind:          ErrTimeout,
+			OriginalError: err,
+		}
+	}
+	return fmt.Errorf("正在发送一个错误的请求: %w", err)
+}
+
+// NewStreamError 将读取流式响应时的错误转换为 ErrStreamInterrupted 类型的错误，超时和空闲超时归类为 ErrTimeout.
+// 原始错误可以通过 errors.Is 判断，例如 context.Canceled、ErrStreamIdleTimeout.
+func NewStreamError(err error) error {
+	kind := ErrStreamInterrupted
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrStreamIdleTimeout) {
+		kind = ErrTimeout
+	}
+	return &APIError{
+		Message:       fmt.Sprintf("error reading stream: %v", err),
+		Kind:          kind,
+		OriginalError: err,
+	}
 }
@@ -0,0 +1,195 @@
+package bigModel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		header        http.Header
+		body          string
+		wantKind      error
+		wantCode      int
+		wantMessage   string
+		wantRequestId string
+		wantRetry     time.Duration
+	}{
+		{name: "nested error string code", status: 429, body: `{"error":{"code":"1302","message":"并发数过高"}}`, wantKind: ErrRateLimited, wantCode: 1302, wantMessage: "并发数过高"},
+		{name: "nested error numeric code", status: 400, body: `{"error":{"code":1214,"message":"参数非法"}}`, wantKind: ErrInvalidRequest, wantCode: 1214, wantMessage: "参数非法"},
+		{name: "flat code with message", status: 400, body: `{"code":1211,"message":"模型不存在"}`, wantKind: ErrModelNotFound, wantCode: 1211, wantMessage: "模型不存在"},
+		{name: "flat code with msg", status: 200, body: `{"code":1113,"msg":"账户已欠费","request_id":"req-body"}`, wantKind: ErrInsufficientBalance, wantCode: 1113, wantMessage: "账户已欠费", wantRequestId: "req-body"},
+		{name: "request id falls back to id", status: 500, body: `{"code":1230,"message":"流程出错","id":"body-id"}`, wantKind: ErrServerError, wantCode: 1230, wantMessage: "流程出错", wantRequestId: "body-id"},
+		{name: "api code overrides status", status: 400, body: `{"error":{"code":"1301","message":"敏感内容"}}`, wantKind: ErrContentFiltered, wantCode: 1301, wantMessage: "敏感内容"},
+		{name: "unknown api code keeps status kind", status: 401, body: `{"error":{"code":"9999","message":"未知"}}`, wantKind: ErrAuth, wantCode: 9999, wantMessage: "未知"},
+		{name: "api code range", status: 200, body: `{"code":1399,"message":"限流"}`, wantKind: ErrRateLimited, wantCode: 1399, wantMessage: "限流"},
+		{name: "html body", status: 404, body: "<html><body>Not Found</body></html>", wantKind: ErrModelNotFound},
+		{name: "html doctype with ok status", status: 200, body: "  <!DOCTYPE html><html></html>", wantKind: ErrModelNotFound},
+		{name: "empty body", status: 200, body: "", wantKind: ErrServerError, wantMessage: "解析响应JSON失败：响应正文为空"},
+		{name: "empty body keeps status kind", status: 401, body: " \n", wantKind: ErrAuth, wantMessage: "解析响应JSON失败：响应正文为空"},
+		{name: "unparseable body", status: 400, body: "bad gateway", wantKind: ErrInvalidRequest, wantMessage: "Bad request"},
+		{name: "status only 402", status: 402, body: "{}", wantKind: ErrInsufficientBalance, wantMessage: "Insufficient account balance"},
+		{name: "status only 504", status: 504, body: "{}", wantKind: ErrTimeout, wantMessage: "Unexpected API response (HTTP 504)"},
+		{
+			name:          "headers",
+			status:        429,
+			header:        http.Header{"Retry-After": []string{"3"}, "X-Request-Id": []string{"req-header"}},
+			body:          `{"error":{"code":"1302","message":"并发数过高"},"request_id":"req-body"}`,
+			wantKind:      ErrRateLimited,
+			wantCode:      1302,
+			wantMessage:   "并发数过高",
+			wantRequestId: "req-header",
+			wantRetry:     3 * time.Second,
+		},
+		{name: "invalid retry-after", status: 429, header: http.Header{"Retry-After": []string{"soon"}}, body: "{}", wantKind: ErrRateLimited, wantMessage: "Rate limit exceeded"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewAPIError(tt.status, tt.header, []byte(tt.body))
+			if got.StatusCode != tt.status || got.ResponseBody != tt.body {
+				t.Fatalf("StatusCode/ResponseBody = %d/%q", got.StatusCode, got.ResponseBody)
+			}
+			if got.Kind != tt.wantKind {
+				t.Fatalf("Kind = %v, want %v", got.Kind, tt.wantKind)
+			}
+			if got.APICode != tt.wantCode {
+				t.Fatalf("APICode = %d, want %d", got.APICode, tt.wantCode)
+			}
+			if tt.wantMessage != "" && got.Message != tt.wantMessage {
+				t.Fatalf("Message = %q, want %q", got.Message, tt.wantMessage)
+			}
+			if got.RequestId != tt.wantRequestId {
+				t.Fatalf("RequestId = %q, want %q", got.RequestId, tt.wantRequestId)
+			}
+			if got.RetryAfter != tt.wantRetry {
+				t.Fatalf("RetryAfter = %v, want %v", got.RetryAfter, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func TestKindFromStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{200, nil},
+		{400, ErrInvalidRequest},
+		{401, ErrAuth},
+		{403, ErrAuth},
+		{402, ErrInsufficientBalance},
+		{404, ErrModelNotFound},
+		{408, ErrTimeout},
+		{422, ErrInvalidRequest},
+		{429, ErrRateLimited},
+		{500, ErrServerError},
+		{503, ErrServerError},
+		{504, ErrTimeout},
+	}
+	for _, tt := range tests {
+		if got := kindFromStatus(tt.status); got != tt.want {
+			t.Errorf("kindFromStatus(%d) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestKindFromAPICode(t *testing.T) {
+	tests := []struct {
+		code int
+		want error
+	}{
+		{0, nil},
+		{1002, ErrAuth},
+		{1099, ErrAuth},
+		{1113, ErrInsufficientBalance},
+		{1211, ErrModelNotFound},
+		{1214, ErrInvalidRequest},
+		{1230, ErrServerError},
+		{1299, ErrInvalidRequest},
+		{1301, ErrContentFiltered},
+		{1302, ErrRateLimited},
+		{1309, ErrInsufficientBalance},
+		{1500, nil},
+	}
+	for _, tt := range tests {
+		if got := kindFromAPICode(tt.code); got != tt.want {
+			t.Errorf("kindFromAPICode(%d) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestAPIErrorIs(t *testing.T) {
+	sentinels := []error{ErrAuth, ErrInsufficientBalance, ErrRateLimited, ErrContentFiltered, ErrModelNotFound, ErrInvalidRequest, ErrServerError, ErrTimeout, ErrStreamInterrupted}
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "auth", err: NewAPIError(401, nil, []byte("{}")), want: ErrAuth},
+		{name: "balance", err: NewAPIError(400, nil, []byte(`{"error":{"code":"1113","message":"欠费"}}`)), want: ErrInsufficientBalance},
+		{name: "rate limited", err: NewAPIError(429, nil, []byte("{}")), want: ErrRateLimited},
+		{name: "content filtered", err: NewAPIError(400, nil, []byte(`{"error":{"code":"1301","message":"敏感"}}`)), want: ErrContentFiltered},
+		{name: "model not found", err: NewAPIError(400, nil, []byte(`{"code":1211,"msg":"模型不存在"}`)), want: ErrModelNotFound},
+		{name: "invalid request", err: NewAPIError(400, nil, []byte("{}")), want: ErrInvalidRequest},
+		{name: "server error", err: NewAPIError(500, nil, []byte("{}")), want: ErrServerError},
+		{name: "timeout", err: NewTransportError(fmt.Errorf("dial: %w", context.DeadlineExceeded)), want: ErrTimeout},
+		{name: "stream interrupted", err: NewStreamError(errors.New("unexpected EOF")), want: ErrStreamInterrupted},
+		{name: "stream idle timeout", err: NewStreamError(ErrStreamIdleTimeout), want: ErrTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("请求失败: %w", tt.err)
+			for _, sentinel := range sentinels {
+				if got := errors.Is(wrapped, sentinel); got != (sentinel == tt.want) {
+					t.Errorf("errors.Is(err, %v) = %v", sentinel, got)
+				}
+			}
+			var apiErr *APIError
+			if !errors.As(wrapped, &apiErr) || apiErr.Kind != tt.want {
+				t.Fatalf("errors.As(*APIError) = %v, %+v", apiErr != nil, apiErr)
+			}
+		})
+	}
+}
+
+func TestNewTransportError(t *testing.T) {
+	err := NewTransportError(errors.New("connection refused"))
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		t.Fatalf("non-timeout transport error should not be an APIError: %v", err)
+	}
+	if errors.Is(err, ErrTimeout) {
+		t.Fatalf("non-timeout transport error classified as ErrTimeout: %v", err)
+	}
+}
+
+func TestHandleAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantCode int
+		wantKind error
+	}{
+		{name: "api error body", body: `{"code":1302,"msg":"并发数过高"}`, wantCode: 1302, wantKind: ErrRateLimited},
+		{name: "truncated json", body: `{"choices":[`, wantKind: ErrServerError},
+		{name: "empty body", body: "", wantKind: ErrServerError},
+		{name: "html body", body: "<html></html>", wantKind: ErrModelNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var apiErr *APIError
+			if err := HandleAPIError([]byte(tt.body)); !errors.As(err, &apiErr) {
+				t.Fatalf("HandleAPIError() = %T, want *APIError", err)
+			}
+			if apiErr.StatusCode != http.StatusOK || apiErr.APICode != tt.wantCode || apiErr.Kind != tt.wantKind {
+				t.Fatalf("HandleAPIError() = %+v", apiErr)
+			}
+		})
+	}
+}
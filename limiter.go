@@ -0,0 +1,181 @@
+package bigModel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// LimiterConfig 客户端限流配置，每个 APIKEY 与模型的组合使用独立的限流桶.
+type LimiterConfig struct {
+	RequestsPerSecond float64 // 每秒允许发送的请求数，0表示不限制
+	Burst             int     // 请求数突发上限，小于1时按1处理
+	TokensPerMinute   int     // 每分钟允许消耗的Token数，按响应中的 Usage.TotalTokens 统计，0表示不限制
+	MaxInFlight       int     // 同时进行中的最大请求数，0表示不限制
+}
+
+// Limiter 客户端限流器，在请求发送前阻塞等待，可在多个 Client 之间共享.
+type Limiter struct {
+	config  LimiterConfig
+	mu      sync.Mutex
+	buckets map[string]*limiterBucket
+}
+
+// limiterBucket 单个 APIKEY 与模型组合的限流状态.
+type limiterBucket struct {
+	mu       sync.Mutex
+	requests float64       // 当前可用的请求数
+	tokens   float64       // 当前可用的Token数，可能因为超额消耗变为负数
+	last     time.Time     // 上次补充的时间
+	inFlight chan struct{} // 进行中的请求
+}
+
+// NewLimiter 创建一个新的限流器.
+func NewLimiter(config LimiterConfig) (*Limiter, error) {
+	if config.RequestsPerSecond < 0 || config.TokensPerMinute < 0 || config.MaxInFlight < 0 {
+		return nil, errors.New("limiter config must not be negative")
+	}
+	if config.Burst < 1 {
+		config.Burst = 1
+	}
+	return &Limiter{config: config, buckets: make(map[string]*limiterBucket)}, nil
+}
+
+// WithLimiter 为API客户端设置限流器.
+func WithLimiter(limiter *Limiter) Option {
+	return func(c *Client) error {
+		c.Limiter = limiter
+		return nil
+	}
+}
+
+// WithLimiterConfig 使用限流配置为API客户端创建并设置限流器.
+func WithLimiterConfig(config LimiterConfig) Option {
+	return func(c *Client) error {
+		limiter, err := NewLimiter(config)
+		if err != nil {
+			return err
+		}
+		c.Limiter = limiter
+		return nil
+	}
+}
+
+// RecordUsage 记录一次调用消耗的Token数，用于每分钟Token数限流；未设置限流器时不做任何处理.
+func (c *Client) RecordUsage(model string, totalTokens int) {
+	if c.Limiter == nil || totalTokens <= 0 {
+		return
+	}
+	c.Limiter.consume(c.limiterKey(model), totalTokens)
+}
+
+// limiterKey 返回限流桶的键.
+func (c *Client) limiterKey(model string) string {
+	return c.AuthToken + "/" + model
+}
+
+// bucket 返回指定键的限流桶，不存在时创建.
+func (l *Limiter) bucket(key string) *limiterBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &limiterBucket{
+			requests: float64(l.config.Burst),
+			tokens:   float64(l.config.TokensPerMinute),
+			last:     time.Now(),
+		}
+		if l.config.MaxInFlight > 0 {
+			b.inFlight = make(chan struct{}, l.config.MaxInFlight)
+		}
+		l.buckets[key] = b
+	}
+	return b
+}
+
+// refill 根据经过的时间补充可用的请求数和Token数，调用方需持有 b.mu.
+func (l *Limiter) refill(b *limiterBucket, now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	b.last = now
+	if l.config.RequestsPerSecond > 0 {
+		b.requests = math.Min(float64(l.config.Burst), b.requests+elapsed*l.config.RequestsPerSecond)
+	}
+	if l.config.TokensPerMinute > 0 {
+		b.tokens = math.Min(float64(l.config.TokensPerMinute), b.tokens+elapsed*float64(l.config.TokensPerMinute)/60)
+	}
+}
+
+// acquire 等待直到允许发送请求，返回的 release 需要在请求结束后调用.
+func (l *Limiter) acquire(ctx context.Context, key string) (release func(), err error) {
+	b := l.bucket(key)
+	release = func() {}
+	if b.inFlight != nil {
+		select {
+		case b.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-b.inFlight }) }
+	}
+	for {
+		b.mu.Lock()
+		l.refill(b, time.Now())
+		var wait time.Duration
+		if l.config.RequestsPerSecond > 0 && b.requests < 1 {
+			wait = time.Duration((1 - b.requests) / l.config.RequestsPerSecond * float64(time.Second))
+		}
+		if l.config.TokensPerMinute > 0 && b.tokens <= 0 {
+			tokenWait := time.Duration((1 - b.tokens) * 60 / float64(l.config.TokensPerMinute) * float64(time.Second))
+			wait = max(wait, tokenWait)
+		}
+		if wait == 0 {
+			if l.config.RequestsPerSecond > 0 {
+				b.requests--
+			}
+			b.mu.Unlock()
+			return release, nil
+		}
+		b.mu.Unlock()
+		if err := sleepContext(ctx, wait); err != nil {
+			release()
+			return nil, err
+		}
+	}
+}
+
+// consume 扣除已消耗的Token数.
+func (l *Limiter) consume(key string, tokens int) {
+	if l.config.TokensPerMinute <= 0 {
+		return
+	}
+	b := l.bucket(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	l.refill(b, time.Now())
+	b.tokens -= float64(tokens)
+}
+
+// releaseOnClose 在响应正文读取完毕或关闭时释放并发名额.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+// Read 读取响应正文，读取结束或出错时释放并发名额.
+func (r *releaseOnClose) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if err != nil {
+		r.release()
+	}
+	return n, err
+}
+
+// Close 关闭响应正文并释放并发名额.
+func (r *releaseOnClose) Close() error {
+	defer r.release()
+	return r.ReadCloser.Close()
+}
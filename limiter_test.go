@@ -0,0 +1,91 @@
+package bigModel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLimiterInFlightRelease(t *testing.T) {
+	tests := []struct {
+		name   string
+		status []int // 每次请求返回的状态码
+		finish func(resp *http.Response)
+	}{
+		{name: "close body", status: []int{200}, finish: func(resp *http.Response) { _ = resp.Body.Close() }},
+		{name: "read to EOF", status: []int{200}, finish: func(resp *http.Response) { _, _ = io.ReadAll(resp.Body) }},
+		{name: "retried response", status: []int{503, 200}, finish: func(resp *http.Response) { _ = resp.Body.Close() }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(tt.status[atomic.AddInt32(&calls, 1)-1])
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+			c, err := NewClientWithOptions("id.secret", WithBaseURL(srv.URL+"/"),
+				WithLimiterConfig(LimiterConfig{MaxInFlight: 1}), WithRetryPolicy(DefaultRetryPolicy()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req := &Request{Path: "chat", Model: "glm-4-flash"}
+			resp, err := c.PostRequest(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bucket := c.Limiter.bucket(c.limiterKey(req.Model))
+			if len(bucket.inFlight) != 1 {
+				t.Fatalf("in flight = %d before finishing, want 1", len(bucket.inFlight))
+			}
+			tt.finish(resp)
+			if len(bucket.inFlight) != 0 {
+				t.Fatalf("in flight = %d after finishing, want 0", len(bucket.inFlight))
+			}
+		})
+	}
+}
+
+func TestLimiterReleaseOnTransportError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL + "/"
+	srv.Close() // 连接会被拒绝
+	c, err := NewClientWithOptions("id.secret", WithBaseURL(url), WithLimiterConfig(LimiterConfig{MaxInFlight: 1}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &Request{Path: "chat", Model: "glm-4-flash"}
+	if _, err := c.PostRequest(context.Background(), req); err == nil {
+		t.Fatal("expected transport error")
+	}
+	if n := len(c.Limiter.bucket(c.limiterKey(req.Model)).inFlight); n != 0 {
+		t.Fatalf("in flight = %d, want 0", n)
+	}
+}
+
+func TestLimiterAcquireCancel(t *testing.T) {
+	limiter, err := NewLimiter(LimiterConfig{MaxInFlight: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := limiter.acquire(context.Background(), "key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := limiter.acquire(ctx, "key"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+	release()
+	release() // 重复释放不能多归还名额
+	if n := len(limiter.bucket("key").inFlight); n != 0 {
+		t.Fatalf("in flight = %d, want 0", n)
+	}
+}
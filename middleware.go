@@ -0,0 +1,101 @@
+package bigModel
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// HTTPDoerFunc 将普通函数适配为 HTTPDoer.
+type HTTPDoerFunc func(req *http.Request) (*http.Response, error)
+
+// Do 调用函数本身发送请求.
+func (f HTTPDoerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// Middleware 包装 HTTPDoer 的中间件，可以在请求发送前后修改请求、记录日志或统计耗时.
+type Middleware func(next HTTPDoer) HTTPDoer
+
+// WithMiddleware 为API客户端追加中间件，按添加顺序由外向内包裹 HTTPClient.
+func WithMiddleware(middlewares ...Middleware) Option {
+	return func(c *Client) error {
+		for _, mw := range middlewares {
+			if mw != nil {
+				c.Middlewares = append(c.Middlewares, mw)
+			}
+		}
+		return nil
+	}
+}
+
+// doer 返回包裹了全部中间件的 HTTPDoer.
+func (c *Client) doer() HTTPDoer {
+	var client HTTPDoer = http.DefaultClient
+	if c.HTTPClient != nil {
+		client = c.HTTPClient
+	}
+	for i := len(c.Middlewares) - 1; i >= 0; i-- {
+		client = c.Middlewares[i](client)
+	}
+	return client
+}
+
+// redactedHeaders 返回隐藏了鉴权信息的请求头副本.
+func redactedHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for key := range redacted {
+		if strings.EqualFold(key, "Authorization") {
+			redacted.Set(key, "Bearer ******")
+		}
+	}
+	return redacted
+}
+
+// LoggingMiddleware 记录每次请求的方式、地址、请求头、响应状态和耗时，Authorization 会被隐藏.
+// logger 为空时使用 log.Default().
+func LoggingMiddleware(logger *log.Logger) Middleware {
+	if logger == nil {
+		logger = log.Default()
+	}
+	return func(next HTTPDoer) HTTPDoer {
+		return HTTPDoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			logger.Printf("bigModel request: %s %s headers=%v", req.Method, req.URL.String(), redactedHeaders(req.Header))
+			resp, err := next.Do(req)
+			if err != nil {
+				logger.Printf("bigModel response: %s %s error=%v cost=%s", req.Method, req.URL.String(), err, time.Since(start))
+				return resp, err
+			}
+			logger.Printf("bigModel response: %s %s status=%d cost=%s", req.Method, req.URL.String(), resp.StatusCode, time.Since(start))
+			return resp, nil
+		})
+	}
+}
+
+// TimingMiddleware 在每次请求收到响应头后回调请求耗时.
+func TimingMiddleware(observe func(req *http.Request, resp *http.Response, err error, cost time.Duration)) Middleware {
+	return func(next HTTPDoer) HTTPDoer {
+		return HTTPDoerFunc(func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			resp, err := next.Do(req)
+			if observe != nil {
+				observe(req, resp, err, time.Since(start))
+			}
+			return resp, err
+		})
+	}
+}
+
+// HeaderMiddleware 为每次请求设置自定义请求头.
+func HeaderMiddleware(headers map[string]string) Middleware {
+	return func(next HTTPDoer) HTTPDoer {
+		return HTTPDoerFunc(func(req *http.Request) (*http.Response, error) {
+			for key, value := range headers {
+				req.Header.Set(key, value)
+			}
+			return next.Do(req)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/dfpopp/bigModel/model/video"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -17,7 +18,7 @@ type QueryConfig struct {
 	APIKey string `json:"api_key"`
 
 	// Timeout specifies the maximum duration to wait for API responses
-	// Optional. Default: 5 minutes
+	// Optional. Default: 5 seconds
 	Timeout time.Duration `json:"timeout"`
 
 	// HTTPClient specifies the client to send HTTP requests.
@@ -25,23 +26,33 @@ type QueryConfig struct {
 	HTTPClient *http.Client `json:"http_client"`
 
 	// BaseURL is your custom bigModel endpoint url
-	// Optional. Default: https://api.bigModel.com/
+	// Optional. Default: https://open.bigmodel.cn/api/
 	BaseURL string `json:"base_url"`
 
-	// Path sets the path for the API request. Defaults to "chat/completions", if not set.
-	// Example usages would be "/c/chat/" or any http after the baseURL extension
+	// Path 查询接口的路径.
+	//
+	// Deprecated: 查询路径由任务 ID 和 ResultPath 拼接而成，该字段不再生效，仅为兼容保留.
 	Path string `json:"path"`
 }
 
-// ChatQuery 查询异步结果
-func ChatQuery(APIKey string, id string) (*chat.ChatCompletionResponse, error) {
-	config := QueryConfig{
-		APIKey:  APIKey,
-		Timeout: 5 * time.Second,
-		Path:    "paas/v4/async-result/" + id,
+// BatchResult 批量查询中单个任务的查询结果.
+type BatchResult[T any] struct {
+	TaskID string // 任务 ID.
+	Result *T     // 查询结果，查询失败时为空.
+	Err    error  // 查询错误.
+}
+
+// NewClient 根据查询配置创建API客户端.
+func NewClient(config *QueryConfig) (*bigModel.Client, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config不能为空")
+	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 5 * time.Second
 	}
 	var opts []bigModel.Option
-	opts = append(opts, bigModel.WithTimeout(config.Timeout))
+	opts = append(opts, bigModel.WithTimeout(timeout))
 	if config.HTTPClient != nil {
 		opts = append(opts, bigModel.WithHTTPClient(config.HTTPClient))
 	}
@@ -53,84 +64,111 @@ func ChatQuery(APIKey string, id string) (*chat.ChatCompletionResponse, error) {
 		}
 		opts = append(opts, bigModel.WithBaseURL(baseURL))
 	}
-	if len(config.Path) > 0 {
-		opts = append(opts, bigModel.WithPath(config.Path))
+	return bigModel.NewClientWithOptions(config.APIKey, opts...)
+}
+
+// ChatQuery 查询异步结果
+func ChatQuery(APIKey string, id string) (*chat.ChatCompletionResponse, error) {
+	cli, err := NewClient(&QueryConfig{APIKey: APIKey})
+	if err != nil {
+		return nil, err
 	}
-	cli, err := bigModel.NewClientWithOptions(config.APIKey, opts...)
+	return ChatQueryWithClient(context.Background(), cli, id)
+}
+
+// VideoQuery 查询视频生成的异步结果
+func VideoQuery(APIKey string, id string) (*video.VideoCompletionResponse, error) {
+	cli, err := NewClient(&QueryConfig{APIKey: APIKey})
 	if err != nil {
 		return nil, err
 	}
-	ctx := context.Background()
-	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, cli.Timeout)
+	return VideoQueryWithClient(context.Background(), cli, id)
+}
+
+// ChatQueryWithClient 使用已有的客户端查询异步对话补全结果，遵循 ctx 的取消.
+func ChatQueryWithClient(ctx context.Context, c *bigModel.Client, id string) (*chat.ChatCompletionResponse, error) {
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
 	defer tcancel()
-	request := map[string]string{}
-	err = bigModel.SetBodyFromStruct(request)(cli)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := cli.GetRequest(ctx)
+	resp, err := getResult(ctx, c, id)
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode >= 400 {
-		return nil, bigModel.HandleError(resp)
-	}
+	defer func() { _ = resp.Body.Close() }()
 	respData, err := chat.HandleChatCompletionResponse(resp)
 	if err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 	return respData, nil
 }
-func VideoQuery(APIKey string, id string) (*video.VideoCompletionResponse, error) {
-	config := QueryConfig{
-		APIKey:  APIKey,
-		Timeout: 5 * time.Second,
-		Path:    "paas/v4/async-result/" + id,
-	}
-	var opts []bigModel.Option
-	opts = append(opts, bigModel.WithTimeout(config.Timeout))
-	if config.HTTPClient != nil {
-		opts = append(opts, bigModel.WithHTTPClient(config.HTTPClient))
-	}
-	if len(config.BaseURL) > 0 {
-		baseURL := config.BaseURL
-		// sdk won't add '/' automatically
-		if !strings.HasSuffix(baseURL, "/") {
-			baseURL = baseURL + "/"
-		}
-		opts = append(opts, bigModel.WithBaseURL(baseURL))
-	}
-	if len(config.Path) > 0 {
-		opts = append(opts, bigModel.WithPath(config.Path))
+
+// VideoQueryWithClient 使用已有的客户端查询视频生成的异步结果，遵循 ctx 的取消.
+func VideoQueryWithClient(ctx context.Context, c *bigModel.Client, id string) (*video.VideoCompletionResponse, error) {
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
-	cli, err := bigModel.NewClientWithOptions(config.APIKey, opts...)
+	defer tcancel()
+	resp, err := getResult(ctx, c, id)
 	if err != nil {
 		return nil, err
 	}
-	ctx := context.Background()
-	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, cli.Timeout)
+	defer func() { _ = resp.Body.Close() }()
+	respData, err := video.HandleVideoCompletionResponse(resp)
 	if err != nil {
-		return nil, fmt.Errorf("error getting timeout context: %w", err)
+		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
-	defer tcancel()
-	request := map[string]string{}
-	err = bigModel.SetBodyFromStruct(request)(cli)
+	return respData, nil
+}
+
+// BatchQuery 以最多 concurrency 个并发查询多个异步任务，结果顺序与 ids 一致.
+// query 可以是 ChatQueryWithClient、VideoQueryWithClient 或其它同签名的查询函数.
+func BatchQuery[T any](ctx context.Context, c *bigModel.Client, ids []string, concurrency int, query func(ctx context.Context, c *bigModel.Client, id string) (*T, error)) []BatchResult[T] {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	results := make([]BatchResult[T], len(ids))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		results[i].TaskID = id
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func(i int, id string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i].Result, results[i].Err = query(ctx, c, id)
+		}(i, id)
+	}
+	wg.Wait()
+	return results
+}
+
+// getResult 发送异步结果查询请求，返回状态正常的响应.
+func getResult(ctx context.Context, c *bigModel.Client, id string) (*http.Response, error) {
+	if c == nil {
+		return nil, fmt.Errorf("client不能为空")
+	}
+	if id == "" {
+		return nil, fmt.Errorf("任务ID不能为空")
+	}
+	req, err := bigModel.NewJSONRequest(ResultPath+id, map[string]string{})
 	if err != nil {
 		return nil, err
 	}
-	resp, err := cli.GetRequest(ctx)
+	resp, err := c.GetRequest(ctx, req)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("请求失败: %w", err)
 	}
 	if resp.StatusCode >= 400 {
 		return nil, bigModel.HandleError(resp)
 	}
-	respData, err := video.HandleVideoCompletionResponse(resp)
-	if err != nil {
-		return nil, fmt.Errorf("error decoding response: %w", err)
-	}
-	return respData, nil
+	return resp, nil
 }
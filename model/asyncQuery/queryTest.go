@@ -1,8 +1,10 @@
 package asyncQuery
 
 import (
+	"context"
 	"fmt"
 	"github.com/dfpopp/bigModel"
+	"os"
 )
 
 func ChatTest() {
@@ -21,3 +23,19 @@ func VideoTest() {
 	}
 	fmt.Println(bigModel.Json_encode(resp))
 }
+func BatchTest() {
+	// APIKEY 从环境变量 BIGMODEL_API_KEY 读取
+	cli, err := NewClient(&QueryConfig{APIKey: os.Getenv("BIGMODEL_API_KEY")})
+	if err != nil {
+		panic(err)
+	}
+	ids := []string{"37971744365697737-8374400315131467404", "37971744365697737-8374400108972951686"}
+	results := BatchQuery(context.Background(), cli, ids, 4, ChatQueryWithClient)
+	for _, result := range results {
+		if result.Err != nil {
+			fmt.Println(result.TaskID, result.Err.Error())
+			continue
+		}
+		fmt.Println(result.TaskID, bigModel.Json_encode(result.Result))
+	}
+}
@@ -0,0 +1,164 @@
+package asyncQuery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"github.com/dfpopp/bigModel/model/chat"
+	"github.com/dfpopp/bigModel/model/video"
+	"io"
+	"time"
+)
+
+// ResultPath 异步任务结果查询接口的路径前缀，后接任务 ID
+const ResultPath = "paas/v4/async-result/"
+
+// 异步任务的处理状态
+const (
+	TaskStatusProcessing = "PROCESSING" // 处理中
+	TaskStatusSuccess    = "SUCCESS"    // 成功
+	TaskStatusFail       = "FAIL"       // 失败
+)
+
+// ErrTaskFailed 异步任务处理失败时返回，可通过 errors.Is 判断.
+var ErrTaskFailed = errors.New("异步任务处理失败")
+
+// TaskFailedError 异步任务以 FAIL 状态结束.
+type TaskFailedError struct {
+	TaskID       string // 任务 ID.
+	TaskStatus   string // 任务最终状态.
+	ResponseBody string // 最后一次查询的响应正文.
+}
+
+// Error returns a string representation of the error.
+func (e *TaskFailedError) Error() string {
+	return fmt.Sprintf("异步任务 %s 处理失败，状态：%s", e.TaskID, e.TaskStatus)
+}
+
+// Is 使 errors.Is(err, ErrTaskFailed) 成立.
+func (e *TaskFailedError) Is(target error) bool {
+	return target == ErrTaskFailed
+}
+
+// WaitOptions 轮询异步任务结果的配置.
+type WaitOptions struct {
+	PollInterval time.Duration                        // 首次轮询间隔，默认2秒
+	MaxInterval  time.Duration                        // 轮询间隔上限，默认30秒
+	Multiplier   float64                              // 每次轮询后间隔的增长倍数，小于等于1时使用固定间隔，默认1.5
+	Timeout      time.Duration                        // 等待的总时长上限，0表示只受 ctx 控制
+	OnProgress   func(attempt int, taskStatus string) // 每次查询后回调当前任务状态
+}
+
+// withDefaults 返回补全默认值后的配置.
+func (o *WaitOptions) withDefaults() WaitOptions {
+	opts := WaitOptions{}
+	if o != nil {
+		opts = *o
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = 2 * time.Second
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = 30 * time.Second
+	}
+	if opts.MaxInterval < opts.PollInterval {
+		opts.MaxInterval = opts.PollInterval
+	}
+	if opts.Multiplier == 0 {
+		opts.Multiplier = 1.5
+	}
+	return opts
+}
+
+// queryResult 查询一次异步任务结果，返回响应正文.
+func queryResult(ctx context.Context, c *bigModel.Client, taskID string) ([]byte, error) {
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error getting timeout context: %w", err)
+	}
+	defer tcancel()
+	resp, err := getResult(ctx, c, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("无法读取响应正文: %w", err)
+	}
+	return body, nil
+}
+
+// WaitForResult 轮询异步任务直到状态为 SUCCESS 或 FAIL，并将最终结果解析为 T.
+// 任务失败时返回 *TaskFailedError；超过 Timeout 或 ctx 截止时间时返回 bigModel.ErrTimeout 类型的错误，ctx 取消时返回包装了 context.Canceled 的错误.
+func WaitForResult[T any](ctx context.Context, c *bigModel.Client, taskID string, opts *WaitOptions) (*T, error) {
+	if c == nil {
+		return nil, fmt.Errorf("client不能为空")
+	}
+	options := opts.withDefaults()
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	interval := options.PollInterval
+	for attempt := 1; ; attempt++ {
+		body, err := queryResult(ctx, c, taskID)
+		if err != nil {
+			return nil, err
+		}
+		var status struct {
+			TaskStatus string `json:"task_status"`
+		}
+		if err := json.Unmarshal(body, &status); err != nil {
+			return nil, bigModel.HandleAPIError(body)
+		}
+		if options.OnProgress != nil {
+			options.OnProgress(attempt, status.TaskStatus)
+		}
+		switch status.TaskStatus {
+		case TaskStatusSuccess:
+			var result T
+			if err := json.Unmarshal(body, &result); err != nil {
+				return nil, bigModel.HandleAPIError(body)
+			}
+			return &result, nil
+		case TaskStatusFail:
+			return nil, &TaskFailedError{TaskID: taskID, TaskStatus: status.TaskStatus, ResponseBody: string(body)}
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, waitAbortedError(taskID, ctx.Err())
+		case <-timer.C:
+		}
+		if options.Multiplier > 1 {
+			interval = min(time.Duration(float64(interval)*options.Multiplier), options.MaxInterval)
+		}
+	}
+}
+
+// waitAbortedError 构造等待被中止时的错误，超过截止时间归类为 bigModel.ErrTimeout.
+func waitAbortedError(taskID string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return &bigModel.APIError{
+			Message:       fmt.Sprintf("等待异步任务 %s 结果超时: %v", taskID, err),
+			Kind:          bigModel.ErrTimeout,
+			OriginalError: err,
+		}
+	}
+	return fmt.Errorf("等待异步任务 %s 结果时中止: %w", taskID, err)
+}
+
+// WaitForChatResult 轮询异步对话补全任务直到完成.
+func WaitForChatResult(ctx context.Context, c *bigModel.Client, taskID string, opts *WaitOptions) (*chat.ChatCompletionResponse, error) {
+	return WaitForResult[chat.ChatCompletionResponse](ctx, c, taskID, opts)
+}
+
+// WaitForVideoResult 轮询视频生成任务直到完成.
+func WaitForVideoResult(ctx context.Context, c *bigModel.Client, taskID string, opts *WaitOptions) (*video.VideoCompletionResponse, error) {
+	return WaitForResult[video.VideoCompletionResponse](ctx, c, taskID, opts)
+}
@@ -0,0 +1,53 @@
+package asyncQuery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dfpopp/bigModel"
+)
+
+func TestWaitForResultAborted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"task_status":"PROCESSING"}`))
+	}))
+	defer srv.Close()
+	c, err := bigModel.NewClientWithOptions("id.secret", bigModel.WithBaseURL(srv.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		cancel      bool
+		wantTimeout bool
+		wantCause   error
+	}{
+		{name: "overall timeout", timeout: 60 * time.Millisecond, wantTimeout: true, wantCause: context.DeadlineExceeded},
+		{name: "cancelled", cancel: true, wantCause: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.cancel {
+				time.AfterFunc(30*time.Millisecond, cancel)
+			}
+			opts := &WaitOptions{PollInterval: 10 * time.Millisecond, Timeout: tt.timeout}
+			_, err := WaitForResult[struct{}](ctx, c, "task-1", opts)
+			if err == nil {
+				t.Fatal("WaitForResult() error = nil")
+			}
+			if errors.Is(err, bigModel.ErrTimeout) != tt.wantTimeout {
+				t.Fatalf("errors.Is(%v, ErrTimeout) = %v", err, !tt.wantTimeout)
+			}
+			if !errors.Is(err, tt.wantCause) {
+				t.Fatalf("errors.Is(%v, %v) = false", err, tt.wantCause)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package audio
+
+import (
+	"context"
+	"github.com/dfpopp/bigModel"
+)
+
+// StreamEventType 流式事件类型.
+type StreamEventType string
+
+const (
+	// StreamEventDelta 转录文本增量
+	StreamEventDelta StreamEventType = "delta"
+	// StreamEventDone 转录完成
+	StreamEventDone StreamEventType = "done"
+	// StreamEventError 流式响应出错
+	StreamEventError StreamEventType = "error"
+)
+
+// 音频转录事件类型
+const (
+	TranscriptTextDelta = "transcript.text.delta" // 正在转录
+	TranscriptTextDone  = "transcript.text.done"  // 转录完成
+)
+
+// StreamEvent 由流式响应块转换出的类型化事件.
+type StreamEvent struct {
+	Type  StreamEventType                 // 事件类型.
+	Delta string                          // 转录文本增量，Type 为 delta 时有效.
+	Text  string                          // 转录的完整内容，Type 为 done 时有效.
+	Chunk *ToTextCompletionStreamResponse // 产生该事件的原始响应块.
+	Err   error                           // 错误信息，Type 为 error 时有效.
+}
+
+// Events 在后台读取流式响应，并以类型化事件的形式发送到返回的通道.
+// 通道在 done 或 error 事件之后关闭，流也会随之关闭；ctx 取消时会发送携带取消原因的 error 事件并结束，
+// 调用方取消 ctx 后可以停止读取，见 bigModel.StreamEvents.
+func Events(ctx context.Context, stream CompletionStreamInterface) <-chan StreamEvent {
+	var done *StreamEvent
+	convert := func(chunk *ToTextCompletionStreamResponse) []StreamEvent {
+		if chunk.Type == TranscriptTextDone {
+			done = &StreamEvent{Type: StreamEventDone, Text: chunk.Text, Chunk: chunk}
+			return nil
+		}
+		if chunk.Delta == "" {
+			return nil
+		}
+		return []StreamEvent{{Type: StreamEventDelta, Delta: chunk.Delta, Chunk: chunk}}
+	}
+	finish := func(err error) StreamEvent {
+		if err != nil {
+			return StreamEvent{Type: StreamEventError, Err: err}
+		}
+		if done == nil {
+			return StreamEvent{Type: StreamEventDone}
+		}
+		return *done
+	}
+	return bigModel.StreamEvents(ctx, stream.Recv, stream.Close, convert, finish)
+}
+
+// OnDelta 读取流式响应直到结束，并对每个事件调用 handler.
+// handler 返回错误时停止读取并返回该错误；流出错或 ctx 取消时返回对应错误；正常结束返回 nil.
+func OnDelta(ctx context.Context, stream CompletionStreamInterface, handler func(event StreamEvent) error) error {
+	return bigModel.HandleStreamEvents(ctx, func(ctx context.Context) <-chan StreamEvent {
+		return Events(ctx, stream)
+	}, streamEventErr, handler)
+}
+
+// streamEventErr 返回 error 事件携带的错误.
+func streamEventErr(event StreamEvent) error {
+	if event.Type == StreamEventError {
+		return event.Err
+	}
+	return nil
+}
+
+// Events 以通道形式返回流式事件，见 Events.
+func (s *CompletionStream) Events(ctx context.Context) <-chan StreamEvent {
+	return Events(ctx, s)
+}
+
+// OnDelta 对每个流式事件调用 handler，见 OnDelta.
+func (s *CompletionStream) OnDelta(ctx context.Context, handler func(event StreamEvent) error) error {
+	return OnDelta(ctx, s, handler)
+}
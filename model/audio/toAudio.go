@@ -7,6 +7,9 @@ import (
 	"io"
 )
 
+// SpeechPath 文本转语音接口的默认路径
+const SpeechPath = "paas/v4/audio/speech"
+
 // ToAudioCompletionRequest 定义文本转音频完成请求的结构.
 type ToAudioCompletionRequest struct {
 	Model            string `json:"model"`                       // 要使用的TTS模型 (required).
@@ -21,19 +24,20 @@ func ToAudioPostRequest(c *bigModel.Client, ctx context.Context, request *ToAudi
 	if request == nil {
 		return nil, fmt.Errorf("请求不能为空")
 	}
-	if c.Path == "" {
-		c.Path = "paas/v4/audio/speech"
+	if err := c.Validate(request); err != nil {
+		return nil, err
 	}
 	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
 	defer tcancel()
-	err = bigModel.SetBodyFromStruct(request)(c)
+	req, err := bigModel.NewJSONRequest(SpeechPath, request)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.PostRequest(ctx)
+	req.Model = request.Model
+	resp, err := c.PostRequest(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
@@ -6,6 +6,9 @@ import (
 	"github.com/dfpopp/bigModel"
 )
 
+// TranscriptionsPath 语音转文本接口的默认路径
+const TranscriptionsPath = "paas/v4/audio/transcriptions"
+
 type SegmentsResult struct {
 	Id    int     `json:"id"`    // 分句序号
 	Start float64 `json:"start"` //分句开始时间
@@ -38,8 +41,8 @@ func ToTextPostRequest(c *bigModel.Client, ctx context.Context, request *ToTextC
 	if request == nil {
 		return nil, fmt.Errorf("请求不能为空")
 	}
-	if c.Path == "" {
-		c.Path = "paas/v4/audio/transcriptions"
+	if err := c.Validate(request); err != nil {
+		return nil, err
 	}
 	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
@@ -62,11 +65,12 @@ func ToTextPostRequest(c *bigModel.Client, ctx context.Context, request *ToTextC
 	if request.UserId != "" {
 		requestData["user_id"] = request.UserId
 	}
-	err = bigModel.SetBodyToForm(requestData, "file")(c)
+	req, err := bigModel.NewFormRequest(TranscriptionsPath, requestData, "file")
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.FormRequest(ctx)
+	req.Model = request.Model
+	resp, err := c.FormRequest(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
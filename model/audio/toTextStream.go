@@ -3,26 +3,26 @@ package audio
 import (
 	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/dfpopp/bigModel"
-	"io"
 	"net/http"
-	"strings"
 )
 
 // CompletionStreamInterface is an interface for receiving streaming chat completion responses.
 type CompletionStreamInterface interface {
 	Recv() (*ToTextCompletionStreamResponse, error)
 	Close() error
+	Events(ctx context.Context) <-chan StreamEvent
+	OnDelta(ctx context.Context, handler func(event StreamEvent) error) error
 }
 
 // CompletionStream implements the ChatCompletionStream interface.
 type CompletionStream struct {
-	Ctx    context.Context    // Context for cancellation.
-	Cancel context.CancelFunc // Cancel function for the context.
-	Resp   *http.Response     // HTTP response from the API call.
-	Reader *bufio.Reader      // Reader for the response body.
+	Ctx     context.Context      // Context for cancellation.
+	Cancel  context.CancelFunc   // Cancel function for the context.
+	Resp    *http.Response       // HTTP response from the API call.
+	Reader  *bufio.Reader        // Reader for the response body.
+	decoder *bigModel.SSEDecoder // 解析响应正文的SSE解析器.
 }
 
 // ToTextCompletionStreamResponse 对话补全业务处理成功.
@@ -40,13 +40,19 @@ func ToTextPostStreamRequest(c *bigModel.Client, ctx context.Context, request *T
 	if request == nil {
 		return nil, fmt.Errorf("请求不能为空")
 	}
-	if c.Path == "" {
-		c.Path = "paas/v4/audio/transcriptions"
+	if err := c.Validate(request); err != nil {
+		return nil, err
 	}
-	ctx, _, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
+	// 超时上下文由返回的流持有，在 Close 时释放
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
+	ctx, cancel := context.WithCancel(ctx)
+	release := func() {
+		cancel()
+		tcancel()
+	}
 	requestData := make(map[string]string)
 	if request.Temperature != "" {
 		requestData["temperature"] = request.Temperature
@@ -64,21 +70,25 @@ func ToTextPostStreamRequest(c *bigModel.Client, ctx context.Context, request *T
 		requestData["user_id"] = request.UserId
 	}
 	requestData["stream"] = "true"
-	err = bigModel.SetBodyToForm(requestData, "file")(c)
+	req, err := bigModel.NewFormRequest(TranscriptionsPath, requestData, "file")
 	if err != nil {
+		release()
 		return nil, err
 	}
-	resp, err := c.FormStreamRequest(ctx)
+	req.Model = request.Model
+	resp, err := c.FormStreamRequest(ctx, req)
 	if err != nil {
+		release()
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
 	if resp.StatusCode >= 400 {
+		defer release()
 		return nil, bigModel.HandleError(resp)
 	}
-	ctx, cancel := context.WithCancel(ctx)
+	resp.Body = bigModel.NewStreamBody(ctx, resp.Body, c.StreamIdleTimeout)
 	stream := &CompletionStream{
 		Ctx:    ctx,
-		Cancel: cancel,
+		Cancel: release,
 		Resp:   resp,
 		Reader: bufio.NewReader(resp.Body),
 	}
@@ -87,28 +97,17 @@ func ToTextPostStreamRequest(c *bigModel.Client, ctx context.Context, request *T
 
 // Recv receives the next response from the stream.
 func (s *CompletionStream) Recv() (*ToTextCompletionStreamResponse, error) {
-	reader := s.Reader
-	for {
-		line, err := reader.ReadString('\n') // Read until newline
-		if err != nil {
-			if err == io.EOF {
-				return nil, io.EOF
-			}
-			return nil, fmt.Errorf("error reading stream: %w", err)
-		}
-		line = strings.TrimSpace(line)
-		if line == "data: [DONE]" {
-			return nil, io.EOF // End of stream
-		}
-		if len(line) > 6 && line[:6] == "data: " {
-			trimmed := line[6:] // Trim the "data: " prefix
-			var response ToTextCompletionStreamResponse
-			if err := json.Unmarshal([]byte(trimmed), &response); err != nil {
-				return nil, fmt.Errorf("unmarshal error: %w, raw data: %s", err, trimmed)
-			}
-			return &response, nil
-		}
+	if s.Ctx != nil && s.Ctx.Err() != nil {
+		return nil, bigModel.NewStreamError(s.Ctx.Err())
+	}
+	if s.decoder == nil {
+		s.decoder = bigModel.NewSSEDecoder(s.Reader, 0)
+	}
+	var response ToTextCompletionStreamResponse
+	if err := s.decoder.NextJSON(&response); err != nil {
+		return nil, err
 	}
+	return &response, nil
 }
 
 // Close terminates the stream.
@@ -0,0 +1,184 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/dfpopp/bigModel"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 语音转文本的音频文件限制
+const (
+	MaxAudioFileSize = 25 << 20         // 音频文件最大25MB
+	MaxAudioDuration = 60 * time.Second // 音频时长最长60秒
+)
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+func (r *ToAudioCompletionRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("model", r.Model)
+	v.CheckRequired("input", r.Input)
+	return v.Err()
+}
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+// 音频文件需为 .wav 或 .mp3，大小不超过25MB，时长不超过60秒.
+func (r *ToTextCompletionRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("model", r.Model)
+	if r.FileName == "" {
+		v.Add("file", "不能为空")
+	} else {
+		checkAudioFile(v, r.FileName)
+	}
+	if r.Temperature != "" {
+		if temperature, err := strconv.ParseFloat(r.Temperature, 64); err != nil {
+			v.Add("temperature", "不是有效的数值: %q", r.Temperature)
+		} else {
+			v.CheckUnitInterval("temperature", temperature)
+		}
+	}
+	v.CheckUserId("user_id", r.UserId)
+	return v.Err()
+}
+
+// checkAudioFile 校验音频文件的格式、大小和时长，无法解析时长时只校验大小.
+func checkAudioFile(v *bigModel.ValidationError, fileName string) {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if ext != ".wav" && ext != ".mp3" {
+		v.Add("file", "仅支持 .wav 和 .mp3 格式，当前为%q", ext)
+		return
+	}
+	info, err := os.Stat(fileName)
+	if err != nil {
+		v.Add("file", "无法读取音频文件: %v", err)
+		return
+	}
+	if info.Size() > MaxAudioFileSize {
+		v.Add("file", "文件大小不能超过25MB，当前为%.2fMB", float64(info.Size())/(1<<20))
+		return
+	}
+	duration, err := audioDuration(fileName, ext, info.Size())
+	if err == nil && duration > MaxAudioDuration {
+		v.Add("file", "音频时长不能超过%v，当前约为%v", MaxAudioDuration, duration.Round(time.Second))
+	}
+}
+
+// errUnknownDuration 无法从文件头解析出音频时长.
+var errUnknownDuration = errors.New("无法解析音频时长")
+
+// audioDuration 根据文件头估算音频时长，mp3 按第一帧的码率估算.
+func audioDuration(fileName, ext string, size int64) (time.Duration, error) {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+	if ext == ".wav" {
+		return wavDuration(f, size)
+	}
+	return mp3Duration(f, size)
+}
+
+// wavDuration 读取 RIFF 头中的 fmt 和 data 块计算时长.
+// 块大小按文件实际大小截断；data 块大小为0或0xFFFFFFFF（流式写入、长度未知）时按文件剩余部分计算.
+func wavDuration(r io.Reader, fileSize int64) (time.Duration, error) {
+	var header [12]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, err
+	}
+	if string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return 0, errUnknownDuration
+	}
+	offset := int64(len(header))
+	var byteRate uint32
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(r, chunk[:]); err != nil {
+			return 0, errUnknownDuration
+		}
+		offset += int64(len(chunk))
+		remaining := fileSize - offset
+		size := int64(binary.LittleEndian.Uint32(chunk[4:8]))
+		switch string(chunk[0:4]) {
+		case "fmt ":
+			if size < 16 || size > remaining {
+				return 0, errUnknownDuration
+			}
+			data := make([]byte, size)
+			if _, err := io.ReadFull(r, data); err != nil {
+				return 0, errUnknownDuration
+			}
+			byteRate = binary.LittleEndian.Uint32(data[8:12])
+			if size%2 == 1 {
+				if _, err := io.CopyN(io.Discard, r, 1); err != nil {
+					return 0, errUnknownDuration
+				}
+			}
+			offset += size + size%2
+		case "data":
+			if byteRate == 0 {
+				return 0, errUnknownDuration
+			}
+			if size == 0 || size == 0xFFFFFFFF || size > remaining {
+				size = remaining
+			}
+			return time.Duration(float64(size) / float64(byteRate) * float64(time.Second)), nil
+		default:
+			if size+size%2 > remaining {
+				return 0, errUnknownDuration
+			}
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return 0, errUnknownDuration
+			}
+			offset += size + size%2
+		}
+	}
+}
+
+// mp3 第三层各版本的码率表，单位 kbps
+var (
+	mpeg1Layer3Bitrates = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mpeg2Layer3Bitrates = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// mp3Duration 跳过 ID3v2 标签后读取第一帧的码率，按固定码率估算时长.
+func mp3Duration(r io.Reader, size int64) (time.Duration, error) {
+	buf := make([]byte, 64<<10)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return 0, errUnknownDuration
+	}
+	buf = buf[:n]
+	offset := 0
+	if len(buf) >= 10 && string(buf[0:3]) == "ID3" {
+		tagSize := int(buf[6]&0x7f)<<21 | int(buf[7]&0x7f)<<14 | int(buf[8]&0x7f)<<7 | int(buf[9]&0x7f)
+		offset = 10 + tagSize
+	}
+	for i := offset; i+4 <= len(buf); i++ {
+		if buf[i] != 0xff || buf[i+1]&0xe0 != 0xe0 {
+			continue
+		}
+		version := (buf[i+1] >> 3) & 0x03 // 3: MPEG1, 2: MPEG2, 0: MPEG2.5
+		layer := (buf[i+1] >> 1) & 0x03   // 1: Layer III
+		if version == 1 || layer != 1 {
+			continue
+		}
+		bitrates := mpeg2Layer3Bitrates
+		if version == 3 {
+			bitrates = mpeg1Layer3Bitrates
+		}
+		bitrate := bitrates[buf[i+2]>>4] * 1000
+		if bitrate == 0 {
+			continue
+		}
+		audioBytes := size - int64(i)
+		return time.Duration(float64(audioBytes*8) / float64(bitrate) * float64(time.Second)), nil
+	}
+	return 0, errUnknownDuration
+}
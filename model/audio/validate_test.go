@@ -0,0 +1,62 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// buildWav 生成一个 16kHz、16bit 单声道的 WAV 文件，fmt 和 data 块大小可以被改写以模拟损坏或流式写入的文件.
+func buildWav(fmtSize, dataSize uint32, payload int) []byte {
+	var b bytes.Buffer
+	b.WriteString("RIFF\xff\xff\xff\xffWAVE")
+	b.WriteString("fmt ")
+	_ = binary.Write(&b, binary.LittleEndian, fmtSize)
+	fmtChunk := make([]byte, 16)
+	binary.LittleEndian.PutUint16(fmtChunk[0:2], 1)
+	binary.LittleEndian.PutUint16(fmtChunk[2:4], 1)
+	binary.LittleEndian.PutUint32(fmtChunk[4:8], 16000)
+	binary.LittleEndian.PutUint32(fmtChunk[8:12], 32000)
+	b.Write(fmtChunk)
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, dataSize)
+	b.Write(make([]byte, payload))
+	return b.Bytes()
+}
+
+func TestWavDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		fmtSize  uint32
+		dataSize uint32
+		want     time.Duration
+		wantErr  bool
+	}{
+		{name: "normal", fmtSize: 16, dataSize: 160000, want: 5 * time.Second},
+		{name: "streaming sentinel", fmtSize: 16, dataSize: 0xFFFFFFFF, want: 5 * time.Second},
+		{name: "zero data size", fmtSize: 16, dataSize: 0, want: 5 * time.Second},
+		{name: "data size larger than file", fmtSize: 16, dataSize: 1 << 30, want: 5 * time.Second},
+		{name: "corrupt fmt size", fmtSize: 0xFFFFFFFF, dataSize: 160000, wantErr: true},
+		{name: "fmt too small", fmtSize: 4, dataSize: 160000, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := buildWav(tt.fmtSize, tt.dataSize, 160000)
+			got, err := wavDuration(bytes.NewReader(data), int64(len(data)))
+			if tt.wantErr {
+				if !errors.Is(err, errUnknownDuration) {
+					t.Fatalf("err = %v, want errUnknownDuration", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("duration = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
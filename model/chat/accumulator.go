@@ -0,0 +1,204 @@
+package chat
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// StreamAccumulator 将流式响应的增量合并为与 PostRequest 返回结构一致的完整响应.
+type StreamAccumulator struct {
+	response ChatCompletionResponse
+	choices  map[int]*choiceAccumulator
+}
+
+// choiceAccumulator 单个结果索引的合并状态.
+type choiceAccumulator struct {
+	role         string
+	content      strings.Builder
+	parts        []ContentPart // 非文本的多模态内容片段，按收到的顺序保存.
+	reasoning    strings.Builder
+	audio        Audio
+	toolCalls    map[int]*ToolCall
+	finishReason string
+}
+
+// NewStreamAccumulator 创建一个新的流式响应合并器.
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{choices: make(map[int]*choiceAccumulator)}
+}
+
+// Add 合并一个流式响应块.
+func (a *StreamAccumulator) Add(chunk *StreamChatCompletionResponse) {
+	if chunk == nil {
+		return
+	}
+	if a.response.ID == "" {
+		a.response.ID = chunk.ID
+	}
+	if a.response.RequestId == "" {
+		a.response.RequestId = chunk.RequestId
+	}
+	if a.response.Created == 0 {
+		a.response.Created = chunk.Created
+	}
+	if a.response.Model == "" {
+		a.response.Model = chunk.Model
+	}
+	if chunk.Usage != nil && (chunk.Usage.TotalTokens > 0 || chunk.Usage.PromptTokens > 0 || chunk.Usage.CompletionTokens > 0) {
+		a.response.Usage = *chunk.Usage
+	}
+	a.response.VideoResult = append(a.response.VideoResult, chunk.VideoResult...)
+	a.response.WebSearch = append(a.response.WebSearch, chunk.WebSearch...)
+	a.response.ContentFilter = append(a.response.ContentFilter, chunk.ContentFilter...)
+	for _, choice := range chunk.Choices {
+		a.addChoice(choice)
+	}
+}
+
+// addChoice 合并单个结果的增量.
+func (a *StreamAccumulator) addChoice(choice StreamChoice) {
+	state, ok := a.choices[choice.Index]
+	if !ok {
+		state = &choiceAccumulator{toolCalls: make(map[int]*ToolCall)}
+		a.choices[choice.Index] = state
+	}
+	delta := choice.Delta
+	if state.role == "" {
+		state.role = delta.Role
+	}
+	switch content := delta.Content.(type) {
+	case string:
+		state.content.WriteString(content)
+	case nil:
+	default:
+		state.parts = append(state.parts, contentParts(content)...)
+	}
+	state.reasoning.WriteString(delta.ReasoningContent)
+	if delta.Audio.Id != "" {
+		state.audio.Id = delta.Audio.Id
+	}
+	state.audio.Data += delta.Audio.Data
+	if delta.Audio.ExpiresAt != "" {
+		state.audio.ExpiresAt = delta.Audio.ExpiresAt
+	}
+	for position, toolCall := range delta.ToolCalls {
+		index := position
+		if toolCall.Index != nil {
+			index = *toolCall.Index
+		}
+		mergeToolCall(state.toolCalls, index, toolCall)
+	}
+	if choice.FinishReason != "" {
+		state.finishReason = choice.FinishReason
+	}
+}
+
+// mergeToolCall 按索引合并工具调用片段，函数参数按顺序拼接.
+func mergeToolCall(toolCalls map[int]*ToolCall, index int, fragment ToolCall) {
+	current, ok := toolCalls[index]
+	if !ok {
+		merged := fragment
+		merged.Index = &index
+		toolCalls[index] = &merged
+		return
+	}
+	if current.ID == "" {
+		current.ID = fragment.ID
+	}
+	if current.Type == "" {
+		current.Type = fragment.Type
+	}
+	if current.Function.Name == "" {
+		current.Function.Name = fragment.Function.Name
+	}
+	current.Function.Arguments += fragment.Function.Arguments
+	if fragment.Mcp.Id != "" {
+		current.Mcp.Id = fragment.Mcp.Id
+	}
+	if fragment.Mcp.Type != "" {
+		current.Mcp.Type = fragment.Mcp.Type
+	}
+	if fragment.Mcp.Name != "" {
+		current.Mcp.Name = fragment.Mcp.Name
+	}
+	if fragment.Mcp.ServerLabel != "" {
+		current.Mcp.ServerLabel = fragment.Mcp.ServerLabel
+	}
+	current.Mcp.Arguments += fragment.Mcp.Arguments
+	current.Mcp.Output += fragment.Mcp.Output
+	current.Mcp.Error += fragment.Mcp.Error
+	current.Mcp.Tools = append(current.Mcp.Tools, fragment.Mcp.Tools...)
+}
+
+// Response 返回当前已合并的完整响应.
+func (a *StreamAccumulator) Response() *ChatCompletionResponse {
+	response := a.response
+	indexes := make([]int, 0, len(a.choices))
+	for index := range a.choices {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	response.Choices = make([]Choice, 0, len(indexes))
+	for _, index := range indexes {
+		state := a.choices[index]
+		role := state.role
+		if role == "" {
+			role = ChatMessageRoleAssistant
+		}
+		var content any = state.content.String()
+		if len(state.parts) > 0 {
+			// 含多模态内容时保持与 PostRequest 相同的内容片段结构，文本放在最前面
+			parts := make([]ContentPart, 0, len(state.parts)+1)
+			if text := state.content.String(); text != "" {
+				parts = append(parts, TextPart(text))
+			}
+			content = append(parts, state.parts...)
+		}
+		message := Message{
+			Role:             role,
+			Content:          content,
+			ReasoningContent: state.reasoning.String(),
+			Audio:            state.audio,
+		}
+		if len(state.toolCalls) > 0 {
+			toolIndexes := make([]int, 0, len(state.toolCalls))
+			for toolIndex := range state.toolCalls {
+				toolIndexes = append(toolIndexes, toolIndex)
+			}
+			sort.Ints(toolIndexes)
+			message.ToolCalls = make([]ToolCall, 0, len(toolIndexes))
+			for _, toolIndex := range toolIndexes {
+				message.ToolCalls = append(message.ToolCalls, *state.toolCalls[toolIndex])
+			}
+		}
+		response.Choices = append(response.Choices, Choice{
+			Index:        index,
+			Message:      message,
+			FinishReason: state.finishReason,
+		})
+	}
+	return &response
+}
+
+// CollectStream 读取流式响应直到结束，返回合并后的完整响应，读取完成后关闭流.
+// 读取中途出错时返回已合并的部分响应和错误.
+func CollectStream(stream CompletionStreamInterface) (*ChatCompletionResponse, error) {
+	if stream == nil {
+		return nil, fmt.Errorf("stream不能为空")
+	}
+	defer func() { _ = stream.Close() }()
+	accumulator := NewStreamAccumulator()
+	for {
+		chunk, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return accumulator.Response(), nil
+		}
+		if err != nil {
+			return accumulator.Response(), err
+		}
+		accumulator.Add(chunk)
+	}
+}
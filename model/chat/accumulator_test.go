@@ -0,0 +1,73 @@
+package chat
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func intPtr(i int) *int { return &i }
+
+func TestStreamAccumulatorToolCalls(t *testing.T) {
+	tests := []struct {
+		name   string
+		deltas [][]ToolCall
+		want   []ToolCall
+	}{
+		{
+			name: "arguments split across chunks",
+			deltas: [][]ToolCall{
+				{{Index: intPtr(0), ID: "call_1", Type: "function", Function: ToolCallFunction{Name: "get_weather", Arguments: `{"city":`}}},
+				{{Index: intPtr(0), Function: ToolCallFunction{Arguments: `"北京"}`}}},
+			},
+			want: []ToolCall{{Index: intPtr(0), ID: "call_1", Type: "function", Function: ToolCallFunction{Name: "get_weather", Arguments: `{"city":"北京"}`}}},
+		},
+		{
+			name: "interleaved parallel calls",
+			deltas: [][]ToolCall{
+				{{Index: intPtr(1), ID: "call_b", Function: ToolCallFunction{Name: "b", Arguments: "{"}}},
+				{{Index: intPtr(0), ID: "call_a", Function: ToolCallFunction{Name: "a", Arguments: "{}"}}},
+				{{Index: intPtr(1), Function: ToolCallFunction{Arguments: "}"}}},
+			},
+			want: []ToolCall{
+				{Index: intPtr(0), ID: "call_a", Function: ToolCallFunction{Name: "a", Arguments: "{}"}},
+				{Index: intPtr(1), ID: "call_b", Function: ToolCallFunction{Name: "b", Arguments: "{}"}},
+			},
+		},
+		{
+			name: "missing index uses position",
+			deltas: [][]ToolCall{
+				{{ID: "call_a", Function: ToolCallFunction{Name: "a", Arguments: "{}"}}, {ID: "call_b", Function: ToolCallFunction{Name: "b", Arguments: "{}"}}},
+			},
+			want: []ToolCall{
+				{Index: intPtr(0), ID: "call_a", Function: ToolCallFunction{Name: "a", Arguments: "{}"}},
+				{Index: intPtr(1), ID: "call_b", Function: ToolCallFunction{Name: "b", Arguments: "{}"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accumulator := NewStreamAccumulator()
+			for _, delta := range tt.deltas {
+				accumulator.Add(&StreamChatCompletionResponse{Choices: []StreamChoice{{Delta: Message{ToolCalls: delta}}}})
+			}
+			got, _ := json.Marshal(accumulator.Response().Choices[0].Message.ToolCalls)
+			want, _ := json.Marshal(tt.want)
+			if string(got) != string(want) {
+				t.Fatalf("tool calls = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestStreamAccumulatorContentParts(t *testing.T) {
+	var parts any
+	_ = json.Unmarshal([]byte(`[{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]`), &parts)
+	accumulator := NewStreamAccumulator()
+	accumulator.Add(&StreamChatCompletionResponse{Choices: []StreamChoice{{Delta: Message{Content: "看图："}}}})
+	accumulator.Add(&StreamChatCompletionResponse{Choices: []StreamChoice{{Delta: Message{Content: parts}}}})
+	got, _ := json.Marshal(accumulator.Response().Choices[0].Message.Content)
+	want := `[{"type":"text","text":"看图："},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]`
+	if string(got) != want {
+		t.Fatalf("content = %s, want %s", got, want)
+	}
+}
@@ -62,9 +62,13 @@ type Function struct {
 }
 
 // Tool 模型可以调用的工具。支持函数调用、知识库检索和网络搜索。使用此参数提供模型可以生成 JSON 输入的函数列表或配置其他工具。最多支持 128 个函数。目前 GLM-4 系列已支持所有 tools，GLM-4.5 已支持 web search 和 retrieval。
+// 不同类型的工具可以在同一个请求中混合使用，序列化时只输出与 Type 对应的字段.
 type Tool struct {
-	Type     string   `json:"type"`     // 工具的类型,仅支持"function" (required).
-	Function Function `json:"function"` // 函数详情 (required).
+	Type      string         `json:"type"`                 // 工具的类型：function、web_search、retrieval、mcp (required).
+	Function  Function       `json:"function"`             // 函数详情，Type 为 function 时有效.
+	WebSearch *WebSearchTool `json:"web_search,omitempty"` // 网络搜索配置，Type 为 web_search 时有效.
+	Retrieval *RetrievalTool `json:"retrieval,omitempty"`  // 知识库检索配置，Type 为 retrieval 时有效.
+	Mcp       *McpServerTool `json:"mcp,omitempty"`        // MCP 服务配置，Type 为 mcp 时有效.
 }
 
 // MsgToolFunction 包含生成的函数名称和 JSON 格式参数.
@@ -153,10 +157,11 @@ type ToolCallMcp struct {
 
 // ToolCall 生成的应该被调用的函数名称和参数.
 type ToolCall struct {
-	ID       string           `json:"id"`       // 命中函数的唯一标识符
-	Type     string           `json:"type"`     // 调用的工具类型，目前仅支持 'function', 'mcp'
-	Function ToolCallFunction `json:"function"` // 包含生成的函数名称和 JSON 格式参数
-	Mcp      ToolCallMcp      `json:"mcp"`      // MCP 工具调用参数
+	Index    *int             `json:"index,omitempty"` // 工具调用在当前消息中的序号，流式响应中用于合并同一工具调用的参数片段
+	ID       string           `json:"id"`              // 命中函数的唯一标识符
+	Type     string           `json:"type"`            // 调用的工具类型，目前仅支持 'function', 'mcp'
+	Function ToolCallFunction `json:"function"`        // 包含生成的函数名称和 JSON 格式参数
+	Mcp      ToolCallMcp      `json:"mcp"`             // MCP 工具调用参数
 }
 
 // Message 表示模型生成的消息.
@@ -203,6 +208,18 @@ type ChatCompletionResponse struct {
 	TaskStatus    string                      `json:"task_status,omitempty"`    // 调用结束时返回的 Token 使用统计.
 }
 
+// ModerationVerdict 根据 content_filter 和各结果的 finish_reason 得出内容安全结论.
+func (r *ChatCompletionResponse) ModerationVerdict() moderations.Verdict {
+	finishReason := ""
+	for _, choice := range r.Choices {
+		if choice.FinishReason == moderations.FinishReasonSensitive {
+			finishReason = choice.FinishReason
+			break
+		}
+	}
+	return moderations.NewVerdict(r.ContentFilter, finishReason)
+}
+
 // ChatCompletionAsyncResponse 对话补全业务处理成功.
 type ChatCompletionAsyncResponse struct {
 	ID         string `json:"id"`          // 任务 ID.
@@ -211,21 +228,33 @@ type ChatCompletionAsyncResponse struct {
 	TaskStatus string `json:"task_status"` // 处理状态，PROCESSING (处理中)、SUCCESS (成功)、FAIL (失败)。结果需要通过查询获取.
 }
 
+// completionsPath 返回对话补全接口的路径：优先使用 Client.Path，未设置时使用 CompletionsPath.
+func completionsPath(c *bigModel.Client) string {
+	if c.Path != "" {
+		return c.Path
+	}
+	return CompletionsPath
+}
+
 // PostChatRequest 发送非stream的聊天请求
 func PostRequest(c *bigModel.Client, ctx context.Context, request *ChatCompletionRequest) (*ChatCompletionResponse, error) {
 	if request == nil {
 		return nil, fmt.Errorf("请求不能为空")
 	}
+	if err := c.Validate(request); err != nil {
+		return nil, err
+	}
 	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
 	defer tcancel()
-	err = bigModel.SetBodyFromStruct(request)(c)
+	req, err := bigModel.NewJSONRequest(completionsPath(c), request)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.PostRequest(ctx)
+	req.Model = request.Model
+	resp, err := c.PostRequest(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
@@ -236,6 +265,7 @@ func PostRequest(c *bigModel.Client, ctx context.Context, request *ChatCompletio
 	if err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
+	c.RecordUsage(request.Model, respData.Usage.TotalTokens)
 	return respData, nil
 }
 
@@ -244,16 +274,20 @@ func AsyncRequest(c *bigModel.Client, ctx context.Context, request *ChatCompleti
 	if request == nil {
 		return nil, fmt.Errorf("请求不能为空")
 	}
+	if err := c.Validate(request); err != nil {
+		return nil, err
+	}
 	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
 	defer tcancel()
-	err = bigModel.SetBodyFromStruct(request)(c)
+	req, err := bigModel.NewJSONRequest(AsyncCompletionsPath, request)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.PostRequest(ctx)
+	req.Model = request.Model
+	resp, err := c.PostRequest(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
@@ -3,29 +3,31 @@ package chat
 import (
 	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/dfpopp/bigModel"
 	"github.com/dfpopp/bigModel/model/video"
 	"github.com/dfpopp/bigModel/tool/moderations"
 	"github.com/dfpopp/bigModel/tool/webSearch"
-	"io"
 	"net/http"
-	"strings"
 )
 
 // ChatCompletionStreamInterface is an interface for receiving streaming chat completion responses.
 type CompletionStreamInterface interface {
 	Recv() (*StreamChatCompletionResponse, error)
 	Close() error
+	Events(ctx context.Context) <-chan StreamEvent
+	OnDelta(ctx context.Context, handler func(event StreamEvent) error) error
 }
 
 // CompletionStream implements the ChatCompletionStream interface.
 type CompletionStream struct {
-	Ctx    context.Context    // Context for cancellation.
-	Cancel context.CancelFunc // Cancel function for the context.
-	Resp   *http.Response     // HTTP response from the API call.
-	Reader *bufio.Reader      // Reader for the response body.
+	Ctx     context.Context      // Context for cancellation.
+	Cancel  context.CancelFunc   // Cancel function for the context.
+	Resp    *http.Response       // HTTP response from the API call.
+	Reader  *bufio.Reader        // Reader for the response body.
+	client  *bigModel.Client     // 用于记录流式响应最终返回的Token消耗.
+	model   string               // 请求调用的模型名称.
+	decoder *bigModel.SSEDecoder // 解析响应正文的SSE解析器.
 }
 type StreamChatCompletionResponse struct {
 	ID            string                      `json:"id"`             // 任务 ID.
@@ -49,59 +51,66 @@ func PostStreamRequest(c *bigModel.Client, ctx context.Context, request *ChatCom
 	if request == nil {
 		return nil, fmt.Errorf("请求不能为空")
 	}
-	ctx, _, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
+	if err := c.Validate(request); err != nil {
+		return nil, err
+	}
+	// 超时上下文由返回的流持有，在 Close 时释放
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
+	ctx, cancel := context.WithCancel(ctx)
+	release := func() {
+		cancel()
+		tcancel()
+	}
 	request.Stream = true
-	err = bigModel.SetBodyFromStruct(request)(c)
+	req, err := bigModel.NewJSONRequest(completionsPath(c), request)
 	if err != nil {
+		release()
 		return nil, err
 	}
-	resp, err := c.PostStreamRequest(ctx)
+	req.Model = request.Model
+	resp, err := c.PostStreamRequest(ctx, req)
 	if err != nil {
+		release()
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
 	if resp.StatusCode >= 400 {
+		defer release()
 		return nil, bigModel.HandleError(resp)
 	}
-	ctx, cancel := context.WithCancel(ctx)
+	resp.Body = bigModel.NewStreamBody(ctx, resp.Body, c.StreamIdleTimeout)
 	stream := &CompletionStream{
 		Ctx:    ctx,
-		Cancel: cancel,
+		Cancel: release,
 		Resp:   resp,
 		Reader: bufio.NewReader(resp.Body),
+		client: c,
+		model:  request.Model,
 	}
 	return stream, nil
 }
 
 // Recv receives the next response from the stream.
 func (s *CompletionStream) Recv() (*StreamChatCompletionResponse, error) {
-	reader := s.Reader
-	for {
-		line, err := reader.ReadString('\n') // Read until newline
-		if err != nil {
-			if err == io.EOF {
-				return nil, io.EOF
-			}
-			return nil, fmt.Errorf("error reading stream: %w", err)
-		}
-		line = strings.TrimSpace(line)
-		if line == "data: [DONE]" {
-			return nil, io.EOF // End of stream
-		}
-		if len(line) > 6 && line[:6] == "data: " {
-			trimmed := line[6:] // Trim the "data: " prefix
-			var response StreamChatCompletionResponse
-			if err := json.Unmarshal([]byte(trimmed), &response); err != nil {
-				return nil, fmt.Errorf("unmarshal error: %w, raw data: %s", err, trimmed)
-			}
-			if response.Usage == nil {
-				response.Usage = &Usage{}
-			}
-			return &response, nil
-		}
+	if s.Ctx != nil && s.Ctx.Err() != nil {
+		return nil, bigModel.NewStreamError(s.Ctx.Err())
+	}
+	if s.decoder == nil {
+		s.decoder = bigModel.NewSSEDecoder(s.Reader, 0)
+	}
+	var response StreamChatCompletionResponse
+	if err := s.decoder.NextJSON(&response); err != nil {
+		return nil, err
+	}
+	if response.Usage != nil && s.client != nil {
+		s.client.RecordUsage(s.model, response.Usage.TotalTokens)
+	}
+	if response.Usage == nil {
+		response.Usage = &Usage{}
 	}
+	return &response, nil
 }
 
 // Close terminates the stream.
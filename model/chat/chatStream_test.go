@@ -0,0 +1,94 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/dfpopp/bigModel"
+)
+
+// stallingHandler 返回一个数据块后不再发送数据，直到客户端断开.
+func stallingHandler(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.Copy(io.Discard, r.Body)
+	w.Header().Set("Content-Type", "text/event-stream")
+	_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"你\"}}]}\n\n"))
+	w.(http.Flusher).Flush()
+	select {
+	case <-r.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+}
+
+func TestStreamInterrupted(t *testing.T) {
+	tests := []struct {
+		name      string
+		idle      time.Duration
+		cancel    bool
+		wantKind  error
+		wantCause error
+	}{
+		{name: "idle timeout", idle: 150 * time.Millisecond, wantKind: bigModel.ErrTimeout, wantCause: bigModel.ErrStreamIdleTimeout},
+		{name: "ctx cancelled", cancel: true, wantKind: bigModel.ErrStreamInterrupted, wantCause: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, stallingHandler)
+			c.StreamIdleTimeout = tt.idle
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			stream, err := PostStreamRequest(c, ctx, &ChatCompletionRequest{
+				Model:    "glm-4-flash",
+				Messages: []ChatCompletionMessage{{Role: "user", Content: "你好"}},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = stream.Close() }()
+			if _, err := stream.Recv(); err != nil {
+				t.Fatalf("first Recv() error = %v", err)
+			}
+			if tt.cancel {
+				time.AfterFunc(50*time.Millisecond, cancel)
+			}
+			start := time.Now()
+			_, err = stream.Recv()
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("Recv() blocked for %v", elapsed)
+			}
+			if !errors.Is(err, tt.wantKind) || !errors.Is(err, tt.wantCause) {
+				t.Fatalf("Recv() error = %v, want %v wrapping %v", err, tt.wantKind, tt.wantCause)
+			}
+		})
+	}
+}
+
+func TestStreamCloseReleasesContext(t *testing.T) {
+	c := newTestClient(t, stallingHandler)
+	c.Timeout = time.Minute
+	stream, err := PostStreamRequest(c, context.Background(), &ChatCompletionRequest{
+		Model:    "glm-4-flash",
+		Messages: []ChatCompletionMessage{{Role: "user", Content: "你好"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := stream.(*CompletionStream)
+	if s.Ctx.Err() != nil {
+		t.Fatalf("stream context done before Close: %v", s.Ctx.Err())
+	}
+	if err := stream.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-s.Ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Close() did not release the stream context")
+	}
+	if _, err := stream.Recv(); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Recv() after Close error = %v, want context.Canceled", err)
+	}
+}
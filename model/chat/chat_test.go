@@ -0,0 +1,66 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfpopp/bigModel"
+)
+
+func TestPostRequestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantKind error
+		wantCode int
+		wrapped  bool // 错误是否经过 "请求失败: %w" 包装
+	}{
+		{
+			name: "api error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-Request-Id", "req-1")
+				w.WriteHeader(http.StatusTooManyRequests)
+				_, _ = w.Write([]byte(`{"error":{"code":"1302","message":"并发数过高"}}`))
+			},
+			wantKind: bigModel.ErrRateLimited,
+			wantCode: 1302,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(io.Discard, r.Body) // 读完请求体后服务端才能感知客户端断开
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			wantKind: bigModel.ErrTimeout,
+			wrapped:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, tt.handler)
+			c.Timeout = 50 * time.Millisecond
+			_, err := PostRequest(c, context.Background(), &ChatCompletionRequest{
+				Model:    "glm-4-flash",
+				Messages: []ChatCompletionMessage{{Role: "user", Content: "你好"}},
+			})
+			var apiErr *bigModel.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("PostRequest() error = %v, want *bigModel.APIError", err)
+			}
+			if !errors.Is(err, tt.wantKind) || apiErr.APICode != tt.wantCode {
+				t.Fatalf("PostRequest() error = %+v, want kind %v code %d", apiErr, tt.wantKind, tt.wantCode)
+			}
+			if tt.wrapped != strings.HasPrefix(err.Error(), "请求失败: ") {
+				t.Fatalf("PostRequest() error = %q, wrapped = %v", err, tt.wrapped)
+			}
+		})
+	}
+}
@@ -10,3 +10,10 @@ const (
 	// ChatMessageRoleTool is the role of a tool message
 	ChatMessageRoleTool = "tool"
 )
+
+const (
+	// CompletionsPath 对话补全接口的默认路径
+	CompletionsPath = "paas/v4/chat/completions"
+	// AsyncCompletionsPath 异步对话补全接口的默认路径
+	AsyncCompletionsPath = "paas/v4/async/chat/completions"
+)
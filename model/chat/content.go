@@ -0,0 +1,137 @@
+package chat
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 多模态消息内容的类型
+const (
+	ContentTypeText       = "text"        // 文本
+	ContentTypeImageUrl   = "image_url"   // 图片，支持 URL 或 Base64 编码
+	ContentTypeVideoUrl   = "video_url"   // 视频
+	ContentTypeFileUrl    = "file_url"    // 文件，例如 PDF、Word
+	ContentTypeInputAudio = "input_audio" // 音频，仅 glm-4-voice 支持
+)
+
+// MediaUrl 图片、视频、文件的地址.
+type MediaUrl struct {
+	Url string `json:"url"` // 媒体的 URL 地址，图片也可以是 Base64 编码.
+}
+
+// InputAudio 音频输入.
+type InputAudio struct {
+	Data   string `json:"data"`   // 音频文件的 Base64 编码.
+	Format string `json:"format"` // 音频格式，支持 wav、mp3.
+}
+
+// ContentPart 多模态消息中的一段内容，Type 决定哪个字段有效.
+type ContentPart struct {
+	Type       string      `json:"type"`                  // 内容类型，见 ContentType 常量 (required).
+	Text       string      `json:"text,omitempty"`        // 文本内容，Type 为 text 时有效.
+	ImageUrl   *MediaUrl   `json:"image_url,omitempty"`   // 图片，Type 为 image_url 时有效.
+	VideoUrl   *MediaUrl   `json:"video_url,omitempty"`   // 视频，Type 为 video_url 时有效.
+	FileUrl    *MediaUrl   `json:"file_url,omitempty"`    // 文件，Type 为 file_url 时有效.
+	InputAudio *InputAudio `json:"input_audio,omitempty"` // 音频，Type 为 input_audio 时有效.
+}
+
+// TextPart 创建文本内容.
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: ContentTypeText, Text: text}
+}
+
+// ImagePart 创建图片内容，url 为图片地址或 Base64 编码.
+func ImagePart(url string) ContentPart {
+	return ContentPart{Type: ContentTypeImageUrl, ImageUrl: &MediaUrl{Url: url}}
+}
+
+// ImageBase64Part 使用图片文件内容创建图片内容，内容以 Base64 编码传输.
+func ImageBase64Part(data []byte) ContentPart {
+	return ImagePart(base64.StdEncoding.EncodeToString(data))
+}
+
+// VideoPart 创建视频内容.
+func VideoPart(url string) ContentPart {
+	return ContentPart{Type: ContentTypeVideoUrl, VideoUrl: &MediaUrl{Url: url}}
+}
+
+// FilePart 创建文件内容.
+func FilePart(url string) ContentPart {
+	return ContentPart{Type: ContentTypeFileUrl, FileUrl: &MediaUrl{Url: url}}
+}
+
+// AudioPart 使用音频文件内容创建音频内容，format 为 wav 或 mp3.
+func AudioPart(data []byte, format string) ContentPart {
+	return ContentPart{Type: ContentTypeInputAudio, InputAudio: &InputAudio{
+		Data:   base64.StdEncoding.EncodeToString(data),
+		Format: format,
+	}}
+}
+
+// UserMessage 创建由多段内容组成的用户消息.
+func UserMessage(parts ...ContentPart) ChatCompletionMessage {
+	return ChatCompletionMessage{Role: ChatMessageRoleUser, Content: parts}
+}
+
+// Text 返回消息的文本内容，多模态内容返回全部文本段落，以换行符连接.
+func (m Message) Text() string {
+	return contentText(m.Content)
+}
+
+// Parts 返回消息的内容段落，字符串内容会转换为单个文本段落.
+func (m Message) Parts() []ContentPart {
+	return contentParts(m.Content)
+}
+
+// Text 返回消息的文本内容，多模态内容返回全部文本段落，以换行符连接.
+func (m ChatCompletionMessage) Text() string {
+	return contentText(m.Content)
+}
+
+// Parts 返回消息的内容段落，字符串内容会转换为单个文本段落.
+func (m ChatCompletionMessage) Parts() []ContentPart {
+	return contentParts(m.Content)
+}
+
+// contentText 提取内容中的文本.
+func contentText(content any) string {
+	if text, ok := content.(string); ok {
+		return text
+	}
+	var texts []string
+	for _, part := range contentParts(content) {
+		if part.Type == ContentTypeText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// contentParts 将字符串、[]ContentPart 或 JSON 解析得到的数组统一转换为 []ContentPart.
+func contentParts(content any) []ContentPart {
+	switch c := content.(type) {
+	case nil:
+		return nil
+	case string:
+		if c == "" {
+			return nil
+		}
+		return []ContentPart{TextPart(c)}
+	case []ContentPart:
+		return c
+	case ContentPart:
+		return []ContentPart{c}
+	}
+	// 响应中的多模态内容由 json.Unmarshal 解析为 []any，需要重新解析
+	data, err := json.Marshal(content)
+	if err != nil {
+		return []ContentPart{TextPart(fmt.Sprint(content))}
+	}
+	var parts []ContentPart
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return []ContentPart{TextPart(string(data))}
+	}
+	return parts
+}
@@ -0,0 +1,381 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ConversationSummaryPrefix 历史对话摘要消息的前缀，用于识别由 Summarizer 生成的摘要.
+const ConversationSummaryPrefix = "【历史对话摘要】"
+
+// ConversationStore 对话消息的持久化存储.
+type ConversationStore interface {
+	// Load 读取对话的全部消息，对话不存在时返回空列表.
+	Load(ctx context.Context, id string) ([]ChatCompletionMessage, error)
+	// Save 保存对话的全部消息.
+	Save(ctx context.Context, id string, messages []ChatCompletionMessage) error
+}
+
+// Summarizer 将被裁剪的历史消息总结为一段文本.
+type Summarizer func(ctx context.Context, messages []ChatCompletionMessage) (string, error)
+
+// Conversation 多轮对话，自动记录模型的回复并持久化，超过 Token 预算时裁剪或总结较早的对话.
+// 系统消息始终保留，工具调用消息与其对应的工具结果消息总是一起保留或裁剪.
+type Conversation struct {
+	ID           string                          // 对话ID (required).
+	Client       *bigModel.Client                // 发送请求的客户端 (required).
+	Request      ChatCompletionRequest           // 请求模板，Messages 会被对话上下文替换 (required: Model).
+	Store        ConversationStore               // 对话存储，默认为内存存储.
+	MaxTokens    int                             // 发送给模型的上下文 Token 预算，0表示按模型的上下文长度自动计算（未知模型不限制），小于0表示不限制.
+	TokenCounter func(ChatCompletionMessage) int // 估算单条消息的 Token 数，默认为 EstimateMessageTokens.
+	Summarizer   Summarizer                      // 超过预算时总结被裁剪的消息，为空时直接丢弃，不影响已保存的历史.
+
+	mu       sync.Mutex
+	messages []ChatCompletionMessage
+}
+
+// NewConversation 创建对话并从 store 中加载历史消息，store 为空时使用内存存储.
+func NewConversation(ctx context.Context, c *bigModel.Client, id string, store ConversationStore) (*Conversation, error) {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	messages, err := store.Load(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("加载对话失败: %w", err)
+	}
+	return &Conversation{ID: id, Client: c, Store: store, messages: messages}, nil
+}
+
+// Messages 返回对话的全部消息.
+func (cv *Conversation) Messages() []ChatCompletionMessage {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	return append([]ChatCompletionMessage(nil), cv.messages...)
+}
+
+// SetSystem 设置系统消息，已有系统消息时替换第一条.
+func (cv *Conversation) SetSystem(ctx context.Context, prompt string) error {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	system := ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: prompt}
+	messages := append([]ChatCompletionMessage(nil), cv.messages...)
+	if len(messages) > 0 && messages[0].Role == ChatMessageRoleSystem && !isSummaryMessage(messages[0]) {
+		messages[0] = system
+	} else {
+		messages = append([]ChatCompletionMessage{system}, messages...)
+	}
+	return cv.save(ctx, messages)
+}
+
+// Append 追加消息并保存，例如工具调用的结果.
+func (cv *Conversation) Append(ctx context.Context, messages ...ChatCompletionMessage) error {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	return cv.save(ctx, append(append([]ChatCompletionMessage(nil), cv.messages...), messages...))
+}
+
+// AppendResponse 将模型响应的第一个结果作为助手消息追加并保存，包括其中的工具调用.
+func (cv *Conversation) AppendResponse(ctx context.Context, resp *ChatCompletionResponse) error {
+	if resp == nil || len(resp.Choices) == 0 {
+		return fmt.Errorf("响应中没有结果")
+	}
+	return cv.Append(ctx, assistantMessage(resp.Choices[0].Message))
+}
+
+// Reset 清空对话中除系统消息以外的全部消息.
+func (cv *Conversation) Reset(ctx context.Context) error {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	system, _ := splitSystemMessages(cv.messages)
+	return cv.save(ctx, append([]ChatCompletionMessage(nil), system...))
+}
+
+// Send 追加消息后将对话上下文发送给模型，并记录模型的回复.
+// 请求失败时本次追加的消息不会被保存.
+func (cv *Conversation) Send(ctx context.Context, messages ...ChatCompletionMessage) (*ChatCompletionResponse, error) {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	history := append(append([]ChatCompletionMessage(nil), cv.messages...), messages...)
+	contextMessages, history, err := cv.fit(ctx, history)
+	if err != nil {
+		return nil, err
+	}
+	req := cv.Request
+	req.Messages = contextMessages
+	resp, err := PostRequest(cv.Client, ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) > 0 {
+		history = append(history, assistantMessage(resp.Choices[0].Message))
+	}
+	if err := cv.save(ctx, history); err != nil {
+		return resp, err
+	}
+	return resp, nil
+}
+
+// Context 返回按 Token 预算裁剪后将要发送给模型的消息，不会修改对话.
+func (cv *Conversation) Context(ctx context.Context) ([]ChatCompletionMessage, error) {
+	cv.mu.Lock()
+	defer cv.mu.Unlock()
+	budget := cv.tokenBudget()
+	if budget <= 0 {
+		return append([]ChatCompletionMessage(nil), cv.messages...), nil
+	}
+	system, units := splitSystemMessages(cv.messages)
+	return flattenUnits(system, units[cv.trim(budget, system, units):]), nil
+}
+
+// save 保存消息并更新内存中的对话，调用方需持有锁.
+func (cv *Conversation) save(ctx context.Context, messages []ChatCompletionMessage) error {
+	if cv.Store != nil {
+		if err := cv.Store.Save(ctx, cv.ID, messages); err != nil {
+			return fmt.Errorf("保存对话失败: %w", err)
+		}
+	}
+	cv.messages = messages
+	return nil
+}
+
+// fit 返回符合 Token 预算的上下文消息以及需要保存的历史消息.
+// 未设置 Summarizer 时只裁剪上下文，历史消息保持不变；设置后被裁剪的消息会被替换为摘要，
+// 摘要同样计入 Token 预算，插入摘要后超出预算的消息会一并总结.
+func (cv *Conversation) fit(ctx context.Context, history []ChatCompletionMessage) ([]ChatCompletionMessage, []ChatCompletionMessage, error) {
+	budget := cv.tokenBudget()
+	if budget <= 0 {
+		return history, history, nil
+	}
+	system, units := splitSystemMessages(history)
+	start := cv.trim(budget, system, units)
+	if start == 0 || cv.Summarizer == nil {
+		return flattenUnits(system, units[start:]), history, nil
+	}
+	for {
+		summary, err := cv.Summarizer(ctx, flattenUnits(nil, units[:start]))
+		if err != nil {
+			return nil, nil, fmt.Errorf("总结历史对话失败: %w", err)
+		}
+		prefix := append(append([]ChatCompletionMessage(nil), system...), ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: ConversationSummaryPrefix + summary})
+		next := start + cv.trim(budget, prefix, units[start:])
+		if next == start {
+			compacted := flattenUnits(prefix, units[start:])
+			return compacted, compacted, nil
+		}
+		start = next
+	}
+}
+
+// tokenBudget 返回上下文消息的 Token 预算，0表示不限制.
+// MaxTokens 为0时使用模型的上下文长度，并预留 Request.MaxTokens 和工具定义占用的 Token.
+func (cv *Conversation) tokenBudget() int {
+	if cv.MaxTokens != 0 {
+		return max(cv.MaxTokens, 0)
+	}
+	info, ok := bigModel.LookupModel(cv.Request.Model)
+	if !ok || info.ContextWindow <= 0 {
+		return 0
+	}
+	reserved := max(cv.Request.MaxTokens, 0) + EstimateRequestTokens(&ChatCompletionRequest{Tools: cv.Request.Tools})
+	return max(info.ContextWindow-reserved, 1)
+}
+
+// trim 从最新的消息开始保留，直到超过 Token 预算，返回保留的第一组消息的下标.
+// 系统消息和最后一组消息总是保留.
+func (cv *Conversation) trim(budget int, system []ChatCompletionMessage, units [][]ChatCompletionMessage) int {
+	budget -= cv.countTokens(system...)
+	start := len(units)
+	for start > 0 {
+		tokens := cv.countTokens(units[start-1]...)
+		if start < len(units) && tokens > budget {
+			break
+		}
+		budget -= tokens
+		start--
+	}
+	return start
+}
+
+// flattenUnits 将 head 和分组后的消息依次拼接为新的消息列表.
+func flattenUnits(head []ChatCompletionMessage, units [][]ChatCompletionMessage) []ChatCompletionMessage {
+	messages := append([]ChatCompletionMessage(nil), head...)
+	for _, unit := range units {
+		messages = append(messages, unit...)
+	}
+	return messages
+}
+
+// countTokens 估算消息的 Token 总数.
+func (cv *Conversation) countTokens(messages ...ChatCompletionMessage) int {
+	counter := cv.TokenCounter
+	if counter == nil {
+		counter = EstimateMessageTokens
+	}
+	total := 0
+	for _, message := range messages {
+		total += counter(message)
+	}
+	return total
+}
+
+// splitSystemMessages 拆分出开头的系统消息，其余消息按可裁剪的最小单位分组：
+// 带工具调用的助手消息与其后的工具结果消息为一组，其余消息各为一组.
+func splitSystemMessages(messages []ChatCompletionMessage) ([]ChatCompletionMessage, [][]ChatCompletionMessage) {
+	i := 0
+	for i < len(messages) && messages[i].Role == ChatMessageRoleSystem && !isSummaryMessage(messages[i]) {
+		i++
+	}
+	var units [][]ChatCompletionMessage
+	for _, message := range messages[i:] {
+		if message.Role == ChatMessageRoleTool && len(units) > 0 {
+			units[len(units)-1] = append(units[len(units)-1], message)
+			continue
+		}
+		units = append(units, []ChatCompletionMessage{message})
+	}
+	return messages[:i], units
+}
+
+// isSummaryMessage 判断消息是否为 Summarizer 生成的摘要.
+func isSummaryMessage(message ChatCompletionMessage) bool {
+	text, ok := message.Content.(string)
+	return ok && message.Role == ChatMessageRoleSystem && strings.HasPrefix(text, ConversationSummaryPrefix)
+}
+
+// NewModelSummarizer 创建使用模型总结历史对话的 Summarizer.
+func NewModelSummarizer(c *bigModel.Client, model string) Summarizer {
+	return func(ctx context.Context, messages []ChatCompletionMessage) (string, error) {
+		var transcript strings.Builder
+		for _, message := range messages {
+			text := message.Text()
+			if isSummaryMessage(message) {
+				text = strings.TrimPrefix(text, ConversationSummaryPrefix)
+			}
+			for _, toolCall := range message.ToolCalls {
+				text += fmt.Sprintf("[调用 %s %s]", toolCall.Function.Name, toolCall.Function.Arguments)
+			}
+			if text != "" {
+				transcript.WriteString(message.Role + ": " + text + "\n")
+			}
+		}
+		resp, err := PostRequest(c, ctx, &ChatCompletionRequest{
+			Model: model,
+			Messages: []ChatCompletionMessage{
+				{Role: ChatMessageRoleSystem, Content: "请用简洁的中文总结以下对话，保留关键事实、结论和未完成的事项，只输出总结内容。"},
+				{Role: ChatMessageRoleUser, Content: transcript.String()},
+			},
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(resp.Choices) == 0 {
+			return "", fmt.Errorf("响应中没有结果")
+		}
+		_, summary := SplitThinking(resp.Choices[0].Message.Text())
+		return summary, nil
+	}
+}
+
+// MemoryStore 将对话保存在内存中，可以被多个对话并发使用.
+type MemoryStore struct {
+	mu    sync.RWMutex
+	items map[string][]ChatCompletionMessage
+}
+
+// NewMemoryStore 创建内存存储.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{items: make(map[string][]ChatCompletionMessage)}
+}
+
+// Load 读取对话的全部消息.
+func (s *MemoryStore) Load(_ context.Context, id string) ([]ChatCompletionMessage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]ChatCompletionMessage(nil), s.items[id]...), nil
+}
+
+// Save 保存对话的全部消息.
+func (s *MemoryStore) Save(_ context.Context, id string, messages []ChatCompletionMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.items[id] = append([]ChatCompletionMessage(nil), messages...)
+	return nil
+}
+
+// FileStore 将每个对话保存为目录下的一个 JSON 文件，文件名为 "对话ID.json".
+type FileStore struct {
+	Dir string // 保存对话文件的目录.
+	mu  sync.Mutex
+}
+
+// NewFileStore 创建 JSON 文件存储，目录不存在时自动创建.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("创建目录失败: %w", err)
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+// path 返回对话文件的路径，对话ID不能包含路径分隔符.
+func (s *FileStore) path(id string) (string, error) {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return "", fmt.Errorf("对话ID不合法: %q", id)
+	}
+	return filepath.Join(s.Dir, id+".json"), nil
+}
+
+// Load 读取对话的全部消息，文件不存在时返回空列表.
+func (s *FileStore) Load(_ context.Context, id string) ([]ChatCompletionMessage, error) {
+	path, err := s.path(id)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var messages []ChatCompletionMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("unmarshal error: %w", err)
+	}
+	return messages, nil
+}
+
+// Save 保存对话的全部消息，先写入临时文件再替换，避免写入中断导致文件损坏.
+func (s *FileStore) Save(_ context.Context, id string, messages []ChatCompletionMessage) error {
+	path, err := s.path(id)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(messages)
+	if err != nil {
+		return fmt.Errorf("marshal error: %w", err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	tmp, err := os.CreateTemp(s.Dir, id+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
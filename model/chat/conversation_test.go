@@ -0,0 +1,58 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestConversationFitReservesSummaryTokens(t *testing.T) {
+	cv := &Conversation{
+		MaxTokens: 30,
+		// 每条消息按字符数计为 Token
+		TokenCounter: func(message ChatCompletionMessage) int { return len([]rune(message.Text())) },
+		Summarizer: func(ctx context.Context, messages []ChatCompletionMessage) (string, error) {
+			return strings.Repeat("s", len(messages)), nil
+		},
+	}
+	var history []ChatCompletionMessage
+	for i := 0; i < 6; i++ {
+		history = append(history,
+			ChatCompletionMessage{Role: ChatMessageRoleUser, Content: "uuuu"},
+			ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: "aaaa"},
+		)
+	}
+	contextMessages, saved, err := cv.fit(context.Background(), history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total := cv.countTokens(contextMessages...); total > cv.MaxTokens {
+		t.Fatalf("context tokens = %d, exceeds budget %d", total, cv.MaxTokens)
+	}
+	if !isSummaryMessage(contextMessages[0]) {
+		t.Fatalf("first message = %+v, want summary", contextMessages[0])
+	}
+	// 摘要覆盖的消息数与保留的消息数之和应等于原始消息数
+	summarized := len([]rune(strings.TrimPrefix(contextMessages[0].Text(), ConversationSummaryPrefix)))
+	if summarized+len(contextMessages)-1 != len(history) {
+		t.Fatalf("summarized %d + kept %d != %d", summarized, len(contextMessages)-1, len(history))
+	}
+	if len(saved) != len(contextMessages) {
+		t.Fatalf("saved %d messages, want %d", len(saved), len(contextMessages))
+	}
+}
+
+func TestConversationDefaultBudgetFromRegistry(t *testing.T) {
+	cv := &Conversation{Request: ChatCompletionRequest{Model: "glm-4-airx", MaxTokens: 4000}}
+	if got := cv.tokenBudget(); got != 4000 {
+		t.Fatalf("budget = %d, want 4000", got)
+	}
+	cv.MaxTokens = -1
+	if got := cv.tokenBudget(); got != 0 {
+		t.Fatalf("budget = %d, want unlimited", got)
+	}
+	cv = &Conversation{Request: ChatCompletionRequest{Model: "my-proxy-model"}}
+	if got := cv.tokenBudget(); got != 0 {
+		t.Fatalf("budget = %d for unknown model, want unlimited", got)
+	}
+}
@@ -0,0 +1,112 @@
+package chat
+
+import (
+	"context"
+	"github.com/dfpopp/bigModel"
+)
+
+// StreamEventType 流式事件类型.
+type StreamEventType string
+
+const (
+	// StreamEventContent 回复内容增量
+	StreamEventContent StreamEventType = "content"
+	// StreamEventReasoning 思维链内容增量
+	StreamEventReasoning StreamEventType = "reasoning"
+	// StreamEventToolCall 工具调用增量
+	StreamEventToolCall StreamEventType = "tool_call"
+	// StreamEventUsage Token 使用统计
+	StreamEventUsage StreamEventType = "usage"
+	// StreamEventDone 流式响应正常结束
+	StreamEventDone StreamEventType = "done"
+	// StreamEventError 流式响应出错
+	StreamEventError StreamEventType = "error"
+)
+
+// StreamEvent 由流式响应块拆分出的类型化事件.
+type StreamEvent struct {
+	Type         StreamEventType               // 事件类型.
+	Index        int                           // 结果索引.
+	Content      string                        // 内容增量，Type 为 content 或 reasoning 时有效.
+	ToolCall     *ToolCall                     // 工具调用增量，Type 为 tool_call 时有效.
+	Usage        *Usage                        // Token 使用统计，Type 为 usage 时有效.
+	FinishReason string                        // 推理终止原因，Type 为 done 时为最后一次返回的终止原因.
+	Chunk        *StreamChatCompletionResponse // 产生该事件的原始响应块，done 和 error 事件为空.
+	Err          error                         // 错误信息，Type 为 error 时有效.
+}
+
+// Events 在后台读取流式响应，并以类型化事件的形式发送到返回的通道.
+// 通道在 done 或 error 事件之后关闭，流也会随之关闭；ctx 取消时会发送携带取消原因的 error 事件并结束，
+// 调用方取消 ctx 后可以停止读取，见 bigModel.StreamEvents.
+func Events(ctx context.Context, stream CompletionStreamInterface) <-chan StreamEvent {
+	finishReason := ""
+	convert := func(chunk *StreamChatCompletionResponse) []StreamEvent {
+		var events []StreamEvent
+		for _, event := range splitChunk(chunk) {
+			if event.FinishReason != "" {
+				finishReason = event.FinishReason
+			}
+			if event.Type != "" {
+				events = append(events, event)
+			}
+		}
+		return events
+	}
+	finish := func(err error) StreamEvent {
+		if err != nil {
+			return StreamEvent{Type: StreamEventError, Err: err}
+		}
+		return StreamEvent{Type: StreamEventDone, FinishReason: finishReason}
+	}
+	return bigModel.StreamEvents(ctx, stream.Recv, stream.Close, convert, finish)
+}
+
+// OnDelta 读取流式响应直到结束，并对每个事件调用 handler.
+// handler 返回错误时停止读取并返回该错误；流出错或 ctx 取消时返回对应错误；正常结束返回 nil.
+func OnDelta(ctx context.Context, stream CompletionStreamInterface, handler func(event StreamEvent) error) error {
+	return bigModel.HandleStreamEvents(ctx, func(ctx context.Context) <-chan StreamEvent {
+		return Events(ctx, stream)
+	}, streamEventErr, handler)
+}
+
+// streamEventErr 返回 error 事件携带的错误.
+func streamEventErr(event StreamEvent) error {
+	if event.Type == StreamEventError {
+		return event.Err
+	}
+	return nil
+}
+
+// splitChunk 将单个响应块拆分为事件，只携带终止原因的事件 Type 为空.
+func splitChunk(chunk *StreamChatCompletionResponse) []StreamEvent {
+	var events []StreamEvent
+	for _, choice := range chunk.Choices {
+		if choice.Delta.ReasoningContent != "" {
+			events = append(events, StreamEvent{Type: StreamEventReasoning, Index: choice.Index, Content: choice.Delta.ReasoningContent, Chunk: chunk})
+		}
+		if content, ok := choice.Delta.Content.(string); ok && content != "" {
+			events = append(events, StreamEvent{Type: StreamEventContent, Index: choice.Index, Content: content, Chunk: chunk})
+		}
+		for i := range choice.Delta.ToolCalls {
+			toolCall := choice.Delta.ToolCalls[i]
+			events = append(events, StreamEvent{Type: StreamEventToolCall, Index: choice.Index, ToolCall: &toolCall, Chunk: chunk})
+		}
+		if choice.FinishReason != "" {
+			events = append(events, StreamEvent{Index: choice.Index, FinishReason: choice.FinishReason})
+		}
+	}
+	if chunk.Usage != nil && chunk.Usage.TotalTokens > 0 {
+		events = append(events, StreamEvent{Type: StreamEventUsage, Usage: chunk.Usage, Chunk: chunk})
+	}
+	return events
+}
+
+// Events 以通道形式返回流式事件，见 Events.
+func (s *CompletionStream) Events(ctx context.Context) <-chan StreamEvent {
+	return Events(ctx, s)
+}
+
+// OnDelta 对每个流式事件调用 handler，见 OnDelta.
+func (s *CompletionStream) OnDelta(ctx context.Context, handler func(event StreamEvent) error) error {
+	return OnDelta(ctx, s, handler)
+}
@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStream 依次返回预设的响应块，之后阻塞直到被关闭.
+type fakeStream struct {
+	chunks    []*StreamChatCompletionResponse
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func newFakeStream(chunks ...*StreamChatCompletionResponse) *fakeStream {
+	return &fakeStream{chunks: chunks, closed: make(chan struct{})}
+}
+
+func (s *fakeStream) Recv() (*StreamChatCompletionResponse, error) {
+	if len(s.chunks) > 0 {
+		chunk := s.chunks[0]
+		s.chunks = s.chunks[1:]
+		return chunk, nil
+	}
+	<-s.closed
+	return nil, io.ErrUnexpectedEOF
+}
+
+// Close 可能被 ForwardStream 从两个协程同时调用.
+func (s *fakeStream) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+	return nil
+}
+
+func (s *fakeStream) Events(ctx context.Context) <-chan StreamEvent { return Events(ctx, s) }
+
+func (s *fakeStream) OnDelta(ctx context.Context, handler func(event StreamEvent) error) error {
+	return OnDelta(ctx, s, handler)
+}
+
+func contentChunk(text string) *StreamChatCompletionResponse {
+	return &StreamChatCompletionResponse{Choices: []StreamChoice{{Delta: Message{Content: text}}}}
+}
+
+func TestEventsCancelDeliversError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var last StreamEvent
+	for event := range Events(ctx, newFakeStream(contentChunk("a"), contentChunk("b"))) {
+		if event.Type == StreamEventContent && event.Content == "b" {
+			cancel()
+		}
+		last = event
+	}
+	if last.Type != StreamEventError || !errors.Is(last.Err, context.Canceled) {
+		t.Fatalf("last event = %+v, want error event with context.Canceled", last)
+	}
+}
+
+func TestOnDeltaHandlerError(t *testing.T) {
+	stop := errors.New("stop")
+	err := OnDelta(context.Background(), newFakeStream(contentChunk("a"), contentChunk("b")), func(event StreamEvent) error {
+		return stop
+	})
+	if !errors.Is(err, stop) {
+		t.Fatalf("err = %v, want %v", err, stop)
+	}
+}
+
+func TestEventsCancelWithoutReading(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		ctx, cancel := context.WithCancel(context.Background())
+		events := Events(ctx, newFakeStream(contentChunk("a"), contentChunk("b"), contentChunk("c")))
+		<-events
+		// 模拟调用方断开连接：取消后不再读取
+		cancel()
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Fatalf("goroutines = %d after cancel, want <= %d", after, before)
+	}
+}
@@ -0,0 +1,278 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"sync"
+	"time"
+)
+
+// ErrToolMaxIterations 工具调用循环超过最大轮数时返回.
+var ErrToolMaxIterations = errors.New("工具调用超过最大轮数")
+
+// ToolHandler 处理一次函数调用，arguments 为模型生成的 JSON 格式参数，返回值作为 tool 消息的内容回传给模型.
+type ToolHandler func(ctx context.Context, arguments string) (string, error)
+
+// ToolRunner 自动执行模型返回的函数调用，并把结果回传给模型，直到模型给出最终回复.
+type ToolRunner struct {
+	Client         *bigModel.Client                    // 发送请求的客户端 (required).
+	MaxIterations  int                                 // 最多请求模型的轮数，默认10.
+	ToolTimeout    time.Duration                       // 单次函数调用的默认超时时间，0表示不限制，可以通过 WithToolTimeout 为单个函数设置.
+	Sequential     bool                                // 是否按顺序执行同一轮中的多个函数调用，默认并发执行.
+	Stream         bool                                // 是否使用流式请求，流式响应会被合并后再处理.
+	ErrorToMessage func(name string, err error) string // 将函数调用错误转换为回传给模型的内容，默认返回 {"error": "..."}.
+
+	tools    []Tool
+	handlers map[string]registeredTool
+}
+
+// registeredTool 已注册的函数处理方法及其配置.
+type registeredTool struct {
+	handler    ToolHandler
+	timeout    time.Duration
+	hasTimeout bool // 是否通过 WithToolTimeout 单独设置了超时时间.
+}
+
+// ToolOption 注册函数时的可选配置.
+type ToolOption func(*registeredTool)
+
+// WithToolTimeout 设置单个函数的超时时间，覆盖 ToolRunner.ToolTimeout，0表示不限制.
+// 超时后不再等待处理方法返回，超时错误会作为函数调用结果回传给模型.
+func WithToolTimeout(d time.Duration) ToolOption {
+	return func(t *registeredTool) {
+		t.timeout, t.hasTimeout = d, true
+	}
+}
+
+// RunResult 工具调用循环的结果.
+type RunResult struct {
+	Response   *ChatCompletionResponse // 模型最后一次的响应.
+	Messages   []ChatCompletionMessage // 包含函数调用、结果以及模型最终回复在内的完整对话消息.
+	Iterations int                     // 请求模型的次数.
+}
+
+// NewToolRunner 创建一个新的工具调用执行器.
+func NewToolRunner(c *bigModel.Client) *ToolRunner {
+	return &ToolRunner{
+		Client:        c,
+		MaxIterations: 10,
+		handlers:      make(map[string]registeredTool),
+	}
+}
+
+// Register 注册一个函数及其处理方法，函数会被自动加入请求的 Tools.
+func (r *ToolRunner) Register(function Function, handler ToolHandler, opts ...ToolOption) error {
+	if function.Name == "" {
+		return fmt.Errorf("函数名称不能为空")
+	}
+	if handler == nil {
+		return fmt.Errorf("函数 %s 的处理方法不能为空", function.Name)
+	}
+	if _, ok := r.handlers[function.Name]; ok {
+		return fmt.Errorf("函数 %s 已注册", function.Name)
+	}
+	tool := registeredTool{handler: handler}
+	for _, opt := range opts {
+		opt(&tool)
+	}
+	r.handlers[function.Name] = tool
+	r.tools = append(r.tools, NewFunctionTool(function))
+	return nil
+}
+
+// Run 发送请求并循环执行函数调用，直到推理终止原因不再是 tool_calls.
+// 超过最大轮数时返回 ErrToolMaxIterations 以及最后一次的结果.
+func (r *ToolRunner) Run(ctx context.Context, request *ChatCompletionRequest) (*RunResult, error) {
+	if request == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+	if r.Client == nil {
+		return nil, fmt.Errorf("client不能为空")
+	}
+	req := r.prepareRequest(request)
+	maxIterations := r.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = 10
+	}
+	result := &RunResult{}
+	for result.Iterations < maxIterations {
+		resp, err := r.send(ctx, req)
+		result.Iterations++
+		if err != nil {
+			result.Messages = req.Messages
+			return result, err
+		}
+		result.Response = resp
+		if len(resp.Choices) == 0 {
+			break
+		}
+		choice := resp.Choices[0]
+		req.Messages = append(req.Messages, assistantMessage(choice.Message))
+		calls := functionCalls(choice.Message.ToolCalls)
+		if choice.FinishReason != "tool_calls" || len(calls) == 0 {
+			break
+		}
+		req.Messages = append(req.Messages, r.execute(ctx, calls)...)
+		if result.Iterations >= maxIterations {
+			result.Messages = req.Messages
+			return result, ErrToolMaxIterations
+		}
+	}
+	result.Messages = req.Messages
+	return result, nil
+}
+
+// prepareRequest 复制请求，并加入已注册但请求中尚未包含的函数.
+func (r *ToolRunner) prepareRequest(request *ChatCompletionRequest) *ChatCompletionRequest {
+	req := *request
+	req.Messages = append([]ChatCompletionMessage(nil), request.Messages...)
+	req.Tools = append([]Tool(nil), request.Tools...)
+	exists := make(map[string]bool, len(req.Tools))
+	for _, tool := range req.Tools {
+		if tool.toolType() == ToolTypeFunction {
+			exists[tool.Function.Name] = true
+		}
+	}
+	for _, tool := range r.tools {
+		if !exists[tool.Function.Name] {
+			req.Tools = append(req.Tools, tool)
+		}
+	}
+	if req.ToolChoice == "" && len(req.Tools) > 0 {
+		req.ToolChoice = "auto"
+	}
+	return &req
+}
+
+// send 按配置发送普通或流式请求.
+func (r *ToolRunner) send(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	if !r.Stream {
+		req.Stream = false
+		return PostRequest(r.Client, ctx, req)
+	}
+	stream, err := PostStreamRequest(r.Client, ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return CollectStream(stream)
+}
+
+// functionCalls 返回需要在本地执行的函数调用.
+func functionCalls(toolCalls []ToolCall) []ToolCall {
+	calls := make([]ToolCall, 0, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		if toolCall.Type == "" || toolCall.Type == ToolTypeFunction {
+			calls = append(calls, toolCall)
+		}
+	}
+	return calls
+}
+
+// assistantMessage 将模型返回的消息及其中的函数调用转换为对话消息.
+func assistantMessage(message Message) ChatCompletionMessage {
+	msg := ChatCompletionMessage{
+		Role:    ChatMessageRoleAssistant,
+		Content: message.Content,
+	}
+	for _, toolCall := range message.ToolCalls {
+		toolType := toolCall.Type
+		if toolType == "" {
+			toolType = ToolTypeFunction
+		}
+		msg.ToolCalls = append(msg.ToolCalls, MsgTool{
+			Id:   toolCall.ID,
+			Type: toolType,
+			Function: MsgToolFunction{
+				Name:      toolCall.Function.Name,
+				Arguments: toolCall.Function.Arguments,
+			},
+		})
+	}
+	return msg
+}
+
+// execute 执行一轮中的全部函数调用，结果按调用顺序返回.
+func (r *ToolRunner) execute(ctx context.Context, calls []ToolCall) []ChatCompletionMessage {
+	messages := make([]ChatCompletionMessage, len(calls))
+	if r.Sequential || len(calls) == 1 {
+		for i, call := range calls {
+			messages[i] = r.call(ctx, call)
+		}
+		return messages
+	}
+	var wg sync.WaitGroup
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call ToolCall) {
+			defer wg.Done()
+			messages[i] = r.call(ctx, call)
+		}(i, call)
+	}
+	wg.Wait()
+	return messages
+}
+
+// call 执行单个函数调用并生成 tool 消息.
+func (r *ToolRunner) call(ctx context.Context, call ToolCall) ChatCompletionMessage {
+	name := call.Function.Name
+	content, err := r.invoke(ctx, name, call.Function.Arguments)
+	if err != nil {
+		content = r.errorMessage(name, err)
+	}
+	return ChatCompletionMessage{
+		Role:       ChatMessageRoleTool,
+		Content:    content,
+		ToolCallID: call.ID,
+	}
+}
+
+// invoke 调用已注册的处理方法，处理方法 panic 时转换为错误.
+// 设置了超时时间时，超时后不再等待处理方法返回，即使处理方法没有响应 ctx 的取消.
+func (r *ToolRunner) invoke(ctx context.Context, name string, arguments string) (string, error) {
+	tool, ok := r.handlers[name]
+	if !ok {
+		return "", fmt.Errorf("未注册的函数: %s", name)
+	}
+	timeout := r.ToolTimeout
+	if tool.hasTimeout {
+		timeout = tool.timeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	type callResult struct {
+		content string
+		err     error
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				done <- callResult{err: fmt.Errorf("函数 %s 执行异常: %v", name, p)}
+			}
+		}()
+		content, err := tool.handler(ctx, arguments)
+		done <- callResult{content: content, err: err}
+	}()
+	select {
+	case result := <-done:
+		return result.content, result.err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return "", fmt.Errorf("函数 %s 执行超时: %w", name, ctx.Err())
+		}
+		return "", ctx.Err()
+	}
+}
+
+// errorMessage 将函数调用错误转换为回传给模型的内容.
+func (r *ToolRunner) errorMessage(name string, err error) string {
+	if r.ErrorToMessage != nil {
+		return r.ErrorToMessage(name, err)
+	}
+	return bigModel.Json_encode(map[string]string{"error": err.Error()})
+}
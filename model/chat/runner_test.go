@@ -0,0 +1,68 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dfpopp/bigModel"
+)
+
+// newTestClient 创建请求指向 handler 的客户端.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *bigModel.Client {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	c, err := bigModel.NewClientWithOptions("id.secret", bigModel.WithBaseURL(srv.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestToolRunnerTimeoutAndFinalMessage(t *testing.T) {
+	var calls int32
+	var toolResult string
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var req ChatCompletionRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if atomic.AddInt32(&calls, 1) == 1 {
+			_, _ = w.Write([]byte(`{"id":"1","choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","tool_calls":[{"id":"call_1","type":"function","function":{"name":"slow","arguments":"{}"}}]}}]}`))
+			return
+		}
+		toolResult = req.Messages[len(req.Messages)-1].Text()
+		_, _ = w.Write([]byte(`{"id":"2","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"done"}}]}`))
+	})
+	runner := NewToolRunner(c)
+	block := make(chan struct{})
+	defer close(block)
+	err := runner.Register(Function{Name: "slow"}, func(ctx context.Context, arguments string) (string, error) {
+		<-block // 忽略 ctx 的处理方法
+		return "late", nil
+	}, WithToolTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := runner.Run(context.Background(), &ChatCompletionRequest{
+		Model:    "glm-4-flash",
+		Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "hi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(toolResult, "超时") {
+		t.Fatalf("tool result = %q, want timeout error", toolResult)
+	}
+	messages := result.Messages
+	if len(messages) != 4 {
+		t.Fatalf("len(messages) = %d, want 4", len(messages))
+	}
+	if last := messages[len(messages)-1]; last.Role != ChatMessageRoleAssistant || last.Text() != "done" {
+		t.Fatalf("last message = %+v, want final assistant reply", last)
+	}
+}
@@ -0,0 +1,453 @@
+package chat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// JSONSchema 使用 JSON Schema 描述的参数结构，仅包含函数调用和结构化输出常用的关键字.
+type JSONSchema struct {
+	Type                 string                 `json:"type,omitempty"`                 // 类型：object、array、string、integer、number、boolean.
+	Description          string                 `json:"description,omitempty"`          // 字段描述，供模型理解字段含义.
+	Enum                 []any                  `json:"enum,omitempty"`                 // 可选值列表.
+	Format               string                 `json:"format,omitempty"`               // 字符串格式，例如 date-time.
+	Minimum              *float64               `json:"minimum,omitempty"`              // 数值最小值.
+	Maximum              *float64               `json:"maximum,omitempty"`              // 数值最大值.
+	MinLength            *int                   `json:"minLength,omitempty"`            // 字符串最小长度.
+	MaxLength            *int                   `json:"maxLength,omitempty"`            // 字符串最大长度.
+	MinItems             *int                   `json:"minItems,omitempty"`             // 数组最少元素个数.
+	MaxItems             *int                   `json:"maxItems,omitempty"`             // 数组最多元素个数.
+	Items                *JSONSchema            `json:"items,omitempty"`                // 数组元素的结构.
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`           // 对象的属性.
+	Required             []string               `json:"required,omitempty"`             // 对象的必填属性.
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"` // map 类型对象的值结构.
+}
+
+// SchemaValidationError 参数不符合 JSON Schema 时返回，包含全部不符合的字段.
+type SchemaValidationError struct {
+	Errors []string // 每一项为 "字段路径: 错误原因".
+}
+
+// Error 实现 error 接口.
+func (e *SchemaValidationError) Error() string {
+	return "参数校验失败: " + strings.Join(e.Errors, "; ")
+}
+
+// functionNamePattern 函数名称只能包含 a-z、A-Z、0-9、下划线和破折号，最大长度为 64.
+var functionNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// FunctionFromStruct 根据结构体 T 生成函数定义，参数结构由字段的 json 标签和 jsonschema 标签决定.
+// jsonschema 标签以逗号分隔，支持 required、description=、enum=(可重复)、format=、minimum=、maximum=、
+// minLength=、maxLength=、minItems=、maxItems=，值中的逗号使用 \, 转义；较长的描述也可以写在 jsonschema_description 标签中.
+//
+//	type WeatherArgs struct {
+//		City string `json:"city" jsonschema:"required,description=城市名称"`
+//		Unit string `json:"unit,omitempty" jsonschema:"enum=celsius,enum=fahrenheit"`
+//	}
+func FunctionFromStruct[T any](name, description string) (Function, error) {
+	if !functionNamePattern.MatchString(name) {
+		return Function{}, fmt.Errorf("函数名称 %q 不合法，只能包含 a-z、A-Z、0-9、下划线和破折号，最大长度为 64", name)
+	}
+	schema, err := SchemaFor[T]()
+	if err != nil {
+		return Function{}, err
+	}
+	if schema.Type != "object" {
+		return Function{}, fmt.Errorf("函数参数必须是结构体，实际为 %s", schema.Type)
+	}
+	properties := make(map[string]interface{}, len(schema.Properties))
+	for key, property := range schema.Properties {
+		properties[key] = property
+	}
+	return Function{
+		Name:        name,
+		Description: description,
+		Parameters: &FunctionParameters{
+			Type:       "object",
+			Properties: properties,
+			Required:   schema.Required,
+		},
+	}, nil
+}
+
+// DecodeArguments 将模型生成的函数参数解析为 T，解析前按照 T 生成的 JSON Schema 校验必填字段、类型、可选值和取值范围.
+func DecodeArguments[T any](function ToolCallFunction) (T, error) {
+	var result T
+	schema, err := SchemaFor[T]()
+	if err != nil {
+		return result, err
+	}
+	arguments := strings.TrimSpace(function.Arguments)
+	if arguments == "" {
+		arguments = "{}"
+	}
+	if err := decodeWithSchema(schema, []byte(arguments), &result); err != nil {
+		return result, fmt.Errorf("函数 %s 参数错误: %w", function.Name, err)
+	}
+	return result, nil
+}
+
+// TypedToolHandler 将接收结构体参数的处理方法转换为 ToolHandler，参数由 DecodeArguments 解析和校验.
+func TypedToolHandler[T any](handler func(ctx context.Context, arguments T) (string, error)) ToolHandler {
+	return func(ctx context.Context, arguments string) (string, error) {
+		args, err := DecodeArguments[T](ToolCallFunction{Arguments: arguments})
+		if err != nil {
+			return "", err
+		}
+		return handler(ctx, args)
+	}
+}
+
+// SchemaFor 根据类型 T 生成 JSON Schema，规则见 FunctionFromStruct.
+func SchemaFor[T any]() (*JSONSchema, error) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return schemaForType(t, make(map[reflect.Type]bool))
+}
+
+// decodeWithSchema 校验 data 是否符合 schema，通过后解析到 v.
+func decodeWithSchema(schema *JSONSchema, data []byte, v any) error {
+	var value any
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("不是有效的JSON: %w", err)
+	}
+	if err := schema.Validate(value); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("unmarshal error: %w", err)
+	}
+	return nil
+}
+
+// schemaForType 生成单个类型的 JSON Schema，visiting 用于避免递归类型无限展开.
+func schemaForType(t reflect.Type, visiting map[reflect.Type]bool) (*JSONSchema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &JSONSchema{Type: "string", Format: "date-time"}, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &JSONSchema{Type: "string"}, nil
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}, nil
+	case reflect.Interface:
+		return &JSONSchema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// []byte 按 base64 字符串编码
+			return &JSONSchema{Type: "string"}, nil
+		}
+		items, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("不支持的 map 键类型: %s", t.Key())
+		}
+		values, err := schemaForType(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &JSONSchema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if visiting[t] {
+			return &JSONSchema{Type: "object"}, nil
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		schema := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+		if err := addStructFields(schema, t, visiting); err != nil {
+			return nil, err
+		}
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("不支持的参数类型: %s", t)
+	}
+}
+
+// addStructFields 将结构体字段加入对象的属性，未命名的嵌入结构体字段会被展开.
+func addStructFields(schema *JSONSchema, t reflect.Type, visiting map[reflect.Type]bool) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			if err := addStructFields(schema, fieldType, visiting); err != nil {
+				return err
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		property, err := schemaForType(field.Type, visiting)
+		if err != nil {
+			return fmt.Errorf("字段 %s: %w", field.Name, err)
+		}
+		required, err := applySchemaTag(property, field)
+		if err != nil {
+			return fmt.Errorf("字段 %s: %w", field.Name, err)
+		}
+		schema.Properties[name] = property
+		if required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return nil
+}
+
+// applySchemaTag 将 jsonschema 标签应用到字段的 Schema，返回字段是否必填.
+// 数组字段的 enum、format、minimum、maximum、minLength、maxLength 作用于数组元素.
+func applySchemaTag(property *JSONSchema, field reflect.StructField) (bool, error) {
+	if description := field.Tag.Get("jsonschema_description"); description != "" {
+		property.Description = description
+	}
+	target := property
+	if property.Type == "array" && property.Items != nil {
+		target = property.Items
+	}
+	required := false
+	for _, option := range splitSchemaTag(field.Tag.Get("jsonschema")) {
+		key, value, _ := strings.Cut(option, "=")
+		var err error
+		switch strings.TrimSpace(key) {
+		case "":
+		case "required":
+			required = true
+		case "description":
+			property.Description = value
+		case "format":
+			target.Format = value
+		case "enum":
+			var enum any
+			enum, err = parseEnumValue(target.Type, value)
+			target.Enum = append(target.Enum, enum)
+		case "minimum":
+			target.Minimum, err = parseFloatOption(value)
+		case "maximum":
+			target.Maximum, err = parseFloatOption(value)
+		case "minLength":
+			target.MinLength, err = parseIntOption(value)
+		case "maxLength":
+			target.MaxLength, err = parseIntOption(value)
+		case "minItems":
+			property.MinItems, err = parseIntOption(value)
+		case "maxItems":
+			property.MaxItems, err = parseIntOption(value)
+		default:
+			return false, fmt.Errorf("不支持的 jsonschema 标签: %s", key)
+		}
+		if err != nil {
+			return false, fmt.Errorf("jsonschema 标签 %s 的值 %q 不合法: %w", key, value, err)
+		}
+	}
+	return required, nil
+}
+
+// splitSchemaTag 按逗号切分标签，\, 表示值中的逗号.
+func splitSchemaTag(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	var (
+		options []string
+		current strings.Builder
+	)
+	for i := 0; i < len(tag); i++ {
+		if tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',' {
+			current.WriteByte(',')
+			i++
+			continue
+		}
+		if tag[i] == ',' {
+			options = append(options, current.String())
+			current.Reset()
+			continue
+		}
+		current.WriteByte(tag[i])
+	}
+	return append(options, current.String())
+}
+
+// parseEnumValue 按字段类型解析可选值.
+func parseEnumValue(schemaType, value string) (any, error) {
+	switch schemaType {
+	case "integer":
+		return strconv.ParseInt(value, 10, 64)
+	case "number":
+		return strconv.ParseFloat(value, 64)
+	case "boolean":
+		return strconv.ParseBool(value)
+	default:
+		return value, nil
+	}
+}
+
+// parseFloatOption 解析数值类型的标签值.
+func parseFloatOption(value string) (*float64, error) {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// parseIntOption 解析整数类型的标签值.
+func parseIntOption(value string) (*int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// Validate 校验由 json.Unmarshal 解析得到的值是否符合 Schema，不符合时返回 *SchemaValidationError.
+func (s *JSONSchema) Validate(value any) error {
+	var errs []string
+	s.validate("$", value, &errs)
+	if len(errs) > 0 {
+		return &SchemaValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// validate 递归校验值，错误追加到 errs.
+func (s *JSONSchema) validate(path string, value any, errs *[]string) {
+	if s == nil || value == nil {
+		return
+	}
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, path+": "+fmt.Sprintf(format, args...))
+	}
+	if !matchesSchemaType(s.Type, value) {
+		fail("类型应为 %s", s.Type)
+		return
+	}
+	if len(s.Enum) > 0 && !containsEnum(s.Enum, value) {
+		fail("取值应为 %v 之一", s.Enum)
+	}
+	switch v := value.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			fail("不能小于 %v", *s.Minimum)
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			fail("不能大于 %v", *s.Maximum)
+		}
+	case string:
+		length := len([]rune(v))
+		if s.MinLength != nil && length < *s.MinLength {
+			fail("长度不能小于 %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			fail("长度不能大于 %d", *s.MaxLength)
+		}
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("元素个数不能小于 %d", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("元素个数不能大于 %d", *s.MaxItems)
+		}
+		for i, item := range v {
+			s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, errs)
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if item, ok := v[name]; !ok || item == nil {
+				*errs = append(*errs, path+"."+name+": 必填字段缺失")
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			item := v[name]
+			if property, ok := s.Properties[name]; ok {
+				property.validate(path+"."+name, item, errs)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(path+"."+name, item, errs)
+			}
+		}
+	}
+}
+
+// matchesSchemaType 判断值是否为 Schema 要求的类型，未指定类型时不校验.
+func matchesSchemaType(schemaType string, value any) bool {
+	switch schemaType {
+	case "":
+		return true
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	default:
+		return true
+	}
+}
+
+// containsEnum 判断值是否在可选值列表中，数值统一按 float64 比较.
+func containsEnum(enum []any, value any) bool {
+	for _, candidate := range enum {
+		switch c := candidate.(type) {
+		case int64:
+			if f, ok := value.(float64); ok && f == float64(c) {
+				return true
+			}
+		case float64:
+			if f, ok := value.(float64); ok && f == c {
+				return true
+			}
+		default:
+			if candidate == value {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type weatherArgs struct {
+	City  string   `json:"city" jsonschema:"required,description=城市名称\\, 例如北京,minLength=1"`
+	Unit  string   `json:"unit,omitempty" jsonschema:"enum=celsius,enum=fahrenheit"`
+	Days  int      `json:"days,omitempty" jsonschema:"minimum=1,maximum=7"`
+	Tags  []string `json:"tags,omitempty" jsonschema:"maxItems=2"`
+	Extra *struct {
+		Note string `json:"note" jsonschema:"required"`
+	} `json:"extra,omitempty"`
+}
+
+func TestSchemaFor(t *testing.T) {
+	schema, err := SchemaFor[weatherArgs]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schema.Type != "object" || !reflect.DeepEqual(schema.Required, []string{"city"}) {
+		t.Fatalf("schema = %+v", schema)
+	}
+	city := schema.Properties["city"]
+	if city == nil || city.Type != "string" || city.Description != "城市名称, 例如北京" {
+		t.Fatalf("city = %+v", city)
+	}
+	if days := schema.Properties["days"]; days.Type != "integer" || *days.Minimum != 1 || *days.Maximum != 7 {
+		t.Fatalf("days = %+v", days)
+	}
+	if tags := schema.Properties["tags"]; tags.Type != "array" || tags.Items.Type != "string" {
+		t.Fatalf("tags = %+v", tags)
+	}
+}
+
+func TestJSONSchemaValidate(t *testing.T) {
+	schema, err := SchemaFor[weatherArgs]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		args string
+		want []string
+	}{
+		{name: "valid", args: `{"city":"北京","unit":"celsius","days":3,"tags":["a"]}`},
+		{name: "missing required", args: `{}`, want: []string{"$.city: 必填字段缺失"}},
+		{name: "wrong type", args: `{"city":1}`, want: []string{"$.city: 类型应为 string"}},
+		{name: "enum", args: `{"city":"北京","unit":"kelvin"}`, want: []string{"$.unit: 取值应为 [celsius fahrenheit] 之一"}},
+		{name: "range and integer", args: `{"city":"北京","days":1.5}`, want: []string{"$.days: 类型应为 integer"}},
+		{name: "maximum", args: `{"city":"北京","days":8}`, want: []string{"$.days: 不能大于 7"}},
+		{name: "array items", args: `{"city":"北京","tags":["a",2,"c"]}`, want: []string{"$.tags: 元素个数不能大于 2", "$.tags[1]: 类型应为 string"}},
+		{name: "nested required", args: `{"city":"北京","extra":{}}`, want: []string{"$.extra.note: 必填字段缺失"}},
+		{name: "min length", args: `{"city":""}`, want: []string{"$.city: 长度不能小于 1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.args), &value); err != nil {
+				t.Fatal(err)
+			}
+			err := schema.Validate(value)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var validationErr *SchemaValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("err = %v, want *SchemaValidationError", err)
+			}
+			if !reflect.DeepEqual(validationErr.Errors, tt.want) {
+				t.Fatalf("errors = %q, want %q", validationErr.Errors, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeArguments(t *testing.T) {
+	args, err := DecodeArguments[weatherArgs](ToolCallFunction{Name: "get_weather", Arguments: `{"city":"北京","days":2}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args.City != "北京" || args.Days != 2 {
+		t.Fatalf("args = %+v", args)
+	}
+	if _, err := DecodeArguments[weatherArgs](ToolCallFunction{Name: "get_weather"}); err == nil {
+		t.Fatal("expected error for missing city")
+	}
+}
@@ -0,0 +1,166 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"regexp"
+	"strings"
+)
+
+// ErrStructuredOutput 多次重试后模型输出仍无法解析为要求的结构时返回.
+var ErrStructuredOutput = errors.New("模型输出不符合要求的JSON结构")
+
+// DefaultStructuredRetries 结构化输出校验失败后默认的重试次数.
+const DefaultStructuredRetries = 2
+
+// StructuredResult 结构化输出的结果.
+type StructuredResult[T any] struct {
+	Value    T                       // 解析后的结构化结果.
+	Response *ChatCompletionResponse // 模型最后一次的响应.
+	Attempts int                     // 请求模型的次数.
+}
+
+// StructuredOptions 结构化输出的配置.
+type StructuredOptions struct {
+	MaxRetries int    // 校验失败后的重试次数，默认 DefaultStructuredRetries，小于0表示不重试.
+	Prompt     string // 注入系统提示词的说明，%s 处替换为 JSON Schema，不包含 %s 时 JSON Schema 追加在末尾；为空时使用默认说明.
+}
+
+// StructuredOption 修改结构化输出配置的方法.
+type StructuredOption func(*StructuredOptions)
+
+// WithStructuredRetries 设置校验失败后的重试次数.
+func WithStructuredRetries(n int) StructuredOption {
+	return func(o *StructuredOptions) {
+		o.MaxRetries = n
+	}
+}
+
+// WithStructuredPrompt 设置注入系统提示词的说明，%s 处替换为 JSON Schema，不包含 %s 时 JSON Schema 追加在末尾.
+func WithStructuredPrompt(prompt string) StructuredOption {
+	return func(o *StructuredOptions) {
+		o.Prompt = prompt
+	}
+}
+
+// defaultStructuredPrompt 默认注入系统提示词的说明.
+const defaultStructuredPrompt = "请严格按照以下 JSON Schema 输出一个 JSON，不要输出 JSON 以外的任何内容：\n%s"
+
+// GenerateStructured 请求模型输出符合 T 结构的 JSON 并解析为 T.
+// 请求会开启 JSON 输出模式，并在系统提示词中注入由 T 生成的 JSON Schema；
+// 输出无法解析或校验失败时，会把错误原因回传给模型重新生成，直到超过重试次数.
+func GenerateStructured[T any](ctx context.Context, c *bigModel.Client, request *ChatCompletionRequest, opts ...StructuredOption) (*StructuredResult[T], error) {
+	if request == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+	options := StructuredOptions{MaxRetries: DefaultStructuredRetries}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	schema, err := SchemaFor[T]()
+	if err != nil {
+		return nil, err
+	}
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal error: %w", err)
+	}
+	prompt := options.Prompt
+	if prompt == "" {
+		prompt = defaultStructuredPrompt
+	}
+	req := *request
+	req.Stream = false
+	req.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	req.Messages = withSystemPrompt(request.Messages, schemaPrompt(prompt, string(schemaJSON)))
+	result := &StructuredResult[T]{}
+	var lastErr error
+	for attempt := 0; attempt <= max(options.MaxRetries, 0); attempt++ {
+		resp, err := PostRequest(c, ctx, &req)
+		result.Attempts++
+		if err != nil {
+			return result, err
+		}
+		result.Response = resp
+		if len(resp.Choices) == 0 {
+			lastErr = fmt.Errorf("响应中没有结果")
+			continue
+		}
+		text := resp.Choices[0].Message.Text()
+		data, err := ExtractJSON(text)
+		if err == nil {
+			var value T
+			if err = decodeWithSchema(schema, []byte(data), &value); err == nil {
+				result.Value = value
+				return result, nil
+			}
+		}
+		lastErr = err
+		req.Messages = append(req.Messages,
+			ChatCompletionMessage{Role: ChatMessageRoleAssistant, Content: text},
+			ChatCompletionMessage{Role: ChatMessageRoleUser, Content: fmt.Sprintf("上一次的输出不符合要求：%v。请修正后只输出符合 JSON Schema 的 JSON。", err)},
+		)
+	}
+	return result, fmt.Errorf("%w: %v", ErrStructuredOutput, lastErr)
+}
+
+// schemaPrompt 将说明中的 %s 占位符替换为 JSON Schema，其余的 % 保持原样；没有占位符时把 JSON Schema 追加在末尾.
+func schemaPrompt(prompt, schema string) string {
+	if !strings.Contains(prompt, schemaPlaceholder) {
+		return prompt + "\n" + schema
+	}
+	return strings.ReplaceAll(prompt, schemaPlaceholder, schema)
+}
+
+// schemaPlaceholder 说明中 JSON Schema 的占位符.
+const schemaPlaceholder = "%s"
+
+// withSystemPrompt 复制消息列表，并把 prompt 追加到第一条系统消息，没有系统消息时在最前面插入一条.
+func withSystemPrompt(messages []ChatCompletionMessage, prompt string) []ChatCompletionMessage {
+	result := make([]ChatCompletionMessage, 0, len(messages)+1)
+	if len(messages) > 0 && messages[0].Role == ChatMessageRoleSystem {
+		system := messages[0]
+		if text := system.Text(); text != "" {
+			prompt = text + "\n\n" + prompt
+		}
+		system.Content = prompt
+		result = append(result, system)
+		return append(result, messages[1:]...)
+	}
+	result = append(result, ChatCompletionMessage{Role: ChatMessageRoleSystem, Content: prompt})
+	return append(result, messages...)
+}
+
+// fencedJSONPattern 匹配 Markdown 代码块.
+var fencedJSONPattern = regexp.MustCompile("(?s)```[a-zA-Z]*\\s*\\n?(.*?)```")
+
+// ExtractJSON 从模型回复中提取 JSON，支持纯 JSON、Markdown 代码块以及夹杂在文字中的 JSON 对象或数组.
+func ExtractJSON(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if json.Valid([]byte(text)) {
+		return text, nil
+	}
+	for _, match := range fencedJSONPattern.FindAllStringSubmatch(text, -1) {
+		if block := strings.TrimSpace(match[1]); json.Valid([]byte(block)) {
+			return block, nil
+		}
+	}
+	// 从第一个 { 或 [ 开始解析出一个完整的 JSON 值，忽略前后的说明文字
+	for start := strings.IndexAny(text, "{["); start >= 0; {
+		decoder := json.NewDecoder(strings.NewReader(text[start:]))
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == nil {
+			return string(bytes.TrimSpace(raw)), nil
+		}
+		next := strings.IndexAny(text[start+1:], "{[")
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	return "", fmt.Errorf("回复中没有找到有效的JSON")
+}
@@ -0,0 +1,47 @@
+package chat
+
+import "testing"
+
+func TestSchemaPrompt(t *testing.T) {
+	tests := []struct {
+		name   string
+		prompt string
+		want   string
+	}{
+		{name: "placeholder", prompt: "输出：\n%s", want: "输出：\n{}"},
+		{name: "no placeholder", prompt: "只输出JSON", want: "只输出JSON\n{}"},
+		{name: "literal percent", prompt: "置信度用0-100%表示，格式：%s", want: "置信度用0-100%表示，格式：{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemaPrompt(tt.prompt, "{}"); got != tt.want {
+				t.Fatalf("schemaPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{name: "plain", text: ` {"a":1} `, want: `{"a":1}`},
+		{name: "fenced", text: "结果如下：\n```json\n{\"a\":1}\n```", want: `{"a":1}`},
+		{name: "embedded", text: `好的 {"a":[1,2]} 以上`, want: `{"a":[1,2]}`},
+		{name: "none", text: "没有JSON", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ExtractJSON(tt.text)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("ExtractJSON() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// GLM-Z1 系列模型在 content 中返回的思考过程标签
+const (
+	ThinkOpenTag  = "<think>"
+	ThinkCloseTag = "</think>"
+)
+
+// SplitThinking 将包含 <think> 标签的文本拆分为思考过程和最终输出，两部分均去除首尾空白.
+// 没有闭合标签时，<think> 之后的内容全部视为思考过程；省略了开始标签时，第一个 </think> 之前的内容视为思考过程.
+func SplitThinking(text string) (reasoning string, content string) {
+	if !strings.Contains(text, ThinkOpenTag) && !strings.Contains(text, ThinkCloseTag) {
+		return "", text
+	}
+	parser := &ThinkParser{Lookahead: -1}
+	r1, c1 := parser.Feed(text)
+	r2, c2 := parser.Flush()
+	return strings.TrimSpace(r1 + r2), strings.TrimSpace(c1 + c2)
+}
+
+// NormalizeThinking 将响应中 <think> 标签内的思考过程移动到 ReasoningContent，Content 只保留最终输出.
+// 已经包含 ReasoningContent 的结果会在原有内容之后追加.
+func NormalizeThinking(resp *ChatCompletionResponse) *ChatCompletionResponse {
+	if resp == nil {
+		return nil
+	}
+	for i := range resp.Choices {
+		message := &resp.Choices[i].Message
+		text, ok := message.Content.(string)
+		if !ok {
+			continue
+		}
+		reasoning, content := SplitThinking(text)
+		if reasoning == "" && content == text {
+			continue
+		}
+		message.Content = content
+		if message.ReasoningContent != "" && reasoning != "" {
+			message.ReasoningContent += "\n"
+		}
+		message.ReasoningContent += reasoning
+	}
+	return resp
+}
+
+// DefaultThinkLookahead 流式解析时，在出现第一个标签之前最多暂存的字节数.
+const DefaultThinkLookahead = 4096
+
+// ThinkParser 增量解析流式输出中的 <think> 标签，标签可以被拆分在多个响应块中.
+// 在出现第一个标签之前，输出会被暂存：先出现单独的 </think> 时（省略了开始标签），暂存的内容作为思考过程，
+// 与 SplitThinking 的结果一致；暂存超过 Lookahead 字节仍没有标签时按最终输出返回，之后多余的 </think> 会被去除.
+type ThinkParser struct {
+	Lookahead int // 出现第一个标签之前最多暂存的字节数，0表示 DefaultThinkLookahead，小于0表示不限制.
+
+	decided   bool   // 是否已经确定开头的内容属于思考过程还是最终输出.
+	inThink   bool   // 当前是否处于 <think> 标签内.
+	pending   string // 尚未确定归属、需要等待后续内容的文本.
+	trimSpace bool   // 标签之后的空白是否需要去除.
+}
+
+// NewThinkParser 创建一个新的 <think> 标签解析器.
+func NewThinkParser() *ThinkParser {
+	return &ThinkParser{}
+}
+
+// Feed 处理一段增量文本，返回其中属于思考过程和最终输出的部分.
+// 可能属于标签的末尾文本以及第一个标签之前的文本会暂存到下一次 Feed 或 Flush 时返回.
+func (p *ThinkParser) Feed(delta string) (reasoning string, content string) {
+	buf := p.pending + delta
+	p.pending = ""
+	if !p.decided {
+		open, closing := strings.Index(buf, ThinkOpenTag), strings.Index(buf, ThinkCloseTag)
+		lookahead := p.Lookahead
+		if lookahead == 0 {
+			lookahead = DefaultThinkLookahead
+		}
+		switch {
+		case closing >= 0 && (open < 0 || closing < open):
+			p.inThink = true
+		case open >= 0, lookahead > 0 && len(buf) > lookahead:
+		default:
+			p.pending = buf
+			return "", ""
+		}
+		p.decided = true
+	}
+	var reasoningBuf, contentBuf strings.Builder
+	for buf != "" {
+		index, tag := strings.Index(buf, ThinkCloseTag), ThinkCloseTag
+		keep := partialTagSuffix(buf, ThinkCloseTag)
+		if !p.inThink {
+			// 思考过程之外只识别 <think>，多余的 </think> 直接去除
+			if open := strings.Index(buf, ThinkOpenTag); open >= 0 && (index < 0 || open < index) {
+				index, tag = open, ThinkOpenTag
+			}
+			keep = max(keep, partialTagSuffix(buf, ThinkOpenTag))
+		}
+		text := buf
+		if index >= 0 {
+			text = buf[:index]
+		} else if keep > 0 {
+			text = buf[:len(buf)-keep]
+			p.pending = buf[len(buf)-keep:]
+		}
+		p.write(text, &reasoningBuf, &contentBuf)
+		if index < 0 {
+			break
+		}
+		buf = buf[index+len(tag):]
+		if tag == ThinkOpenTag || p.inThink {
+			p.inThink = !p.inThink
+		}
+		p.trimSpace = true
+	}
+	return reasoningBuf.String(), contentBuf.String()
+}
+
+// Flush 返回暂存的文本，流结束时调用.
+func (p *ThinkParser) Flush() (reasoning string, content string) {
+	var reasoningBuf, contentBuf strings.Builder
+	p.decided = true
+	p.write(p.pending, &reasoningBuf, &contentBuf)
+	p.pending = ""
+	return reasoningBuf.String(), contentBuf.String()
+}
+
+// write 按当前状态写入思考过程或最终输出，标签之后紧跟的空白会被去除.
+func (p *ThinkParser) write(text string, reasoning, content *strings.Builder) {
+	if p.trimSpace {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if text == "" {
+			return
+		}
+		p.trimSpace = false
+	}
+	if p.inThink {
+		reasoning.WriteString(text)
+	} else {
+		content.WriteString(text)
+	}
+}
+
+// partialTagSuffix 返回 text 末尾可能是 tag 开头部分的长度.
+func partialTagSuffix(text, tag string) int {
+	for n := min(len(text), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(text, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
+
+// ThinkingStream 包装流式响应，将 <think> 标签内的增量移动到 ReasoningContent.
+type ThinkingStream struct {
+	stream  CompletionStreamInterface
+	parsers map[int]*ThinkParser
+	last    *StreamChatCompletionResponse
+	flushed bool
+}
+
+// NewThinkingStream 创建一个将 <think> 标签转换为 ReasoningContent 的流式响应.
+// 出现第一个标签之前的输出最多暂存 DefaultThinkLookahead 字节，以便识别省略了开始标签的思考过程.
+func NewThinkingStream(stream CompletionStreamInterface) *ThinkingStream {
+	return &ThinkingStream{stream: stream, parsers: make(map[int]*ThinkParser)}
+}
+
+// Recv 读取下一个响应块，流结束前会先返回被暂存的文本.
+func (s *ThinkingStream) Recv() (*StreamChatCompletionResponse, error) {
+	chunk, err := s.stream.Recv()
+	if errors.Is(err, io.EOF) {
+		if flush := s.flush(); flush != nil {
+			return flush, nil
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	for i := range chunk.Choices {
+		choice := &chunk.Choices[i]
+		text, ok := choice.Delta.Content.(string)
+		if !ok {
+			continue
+		}
+		parser, ok := s.parsers[choice.Index]
+		if !ok {
+			parser = NewThinkParser()
+			s.parsers[choice.Index] = parser
+		}
+		reasoning, content := parser.Feed(text)
+		choice.Delta.Content = content
+		choice.Delta.ReasoningContent += reasoning
+	}
+	s.last = chunk
+	return chunk, nil
+}
+
+// flush 在流结束时生成包含暂存文本的响应块，没有暂存文本时返回 nil.
+func (s *ThinkingStream) flush() *StreamChatCompletionResponse {
+	if s.flushed {
+		return nil
+	}
+	s.flushed = true
+	var choices []StreamChoice
+	for index, parser := range s.parsers {
+		reasoning, content := parser.Flush()
+		if reasoning == "" && content == "" {
+			continue
+		}
+		choices = append(choices, StreamChoice{
+			Index: index,
+			Delta: Message{Role: ChatMessageRoleAssistant, Content: content, ReasoningContent: reasoning},
+		})
+	}
+	if len(choices) == 0 {
+		return nil
+	}
+	chunk := &StreamChatCompletionResponse{Choices: choices}
+	if s.last != nil {
+		chunk.ID = s.last.ID
+		chunk.RequestId = s.last.RequestId
+		chunk.Created = s.last.Created
+		chunk.Model = s.last.Model
+	}
+	return chunk
+}
+
+// Close 关闭被包装的流.
+func (s *ThinkingStream) Close() error {
+	return s.stream.Close()
+}
+
+// Events 以通道形式返回流式事件，见 Events.
+func (s *ThinkingStream) Events(ctx context.Context) <-chan StreamEvent {
+	return Events(ctx, s)
+}
+
+// OnDelta 对每个流式事件调用 handler，见 OnDelta.
+func (s *ThinkingStream) OnDelta(ctx context.Context, handler func(event StreamEvent) error) error {
+	return OnDelta(ctx, s, handler)
+}
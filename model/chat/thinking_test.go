@@ -0,0 +1,75 @@
+package chat
+
+import "testing"
+
+func TestSplitThinking(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		wantReasoning string
+		wantContent   string
+	}{
+		{name: "no tags", text: "answer", wantContent: "answer"},
+		{name: "both tags", text: "<think>\nreasoning\n</think>\n\nanswer", wantReasoning: "reasoning", wantContent: "answer"},
+		{name: "unclosed", text: "<think>reasoning", wantReasoning: "reasoning"},
+		{name: "lone close tag", text: "reasoning</think>answer", wantReasoning: "reasoning", wantContent: "answer"},
+		{name: "content before think", text: "a<think>r</think>b", wantReasoning: "r", wantContent: "ab"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasoning, content := SplitThinking(tt.text)
+			if reasoning != tt.wantReasoning || content != tt.wantContent {
+				t.Fatalf("SplitThinking() = (%q, %q), want (%q, %q)", reasoning, content, tt.wantReasoning, tt.wantContent)
+			}
+		})
+	}
+}
+
+// feedChunks 按固定大小拆分文本并依次交给解析器，返回合并后的结果.
+func feedChunks(parser *ThinkParser, text string, size int) (string, string) {
+	var reasoning, content string
+	for i := 0; i < len(text); i += size {
+		r, c := parser.Feed(text[i:min(i+size, len(text))])
+		reasoning += r
+		content += c
+	}
+	r, c := parser.Flush()
+	return reasoning + r, content + c
+}
+
+func TestThinkParserChunks(t *testing.T) {
+	tests := []struct {
+		name          string
+		text          string
+		wantReasoning string
+		wantContent   string
+	}{
+		{name: "both tags", text: "<think>\n先分析问题</think>\n\n最终答案<b>", wantReasoning: "先分析问题", wantContent: "最终答案<b>"},
+		{name: "lone close tag", text: "先分析问题</think>\n最终答案", wantReasoning: "先分析问题", wantContent: "最终答案"},
+		{name: "no tags", text: "最终答案", wantContent: "最终答案"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 按每种块大小拆分，标签可能被切断在任意位置
+			for size := 1; size <= len(tt.text); size++ {
+				reasoning, content := feedChunks(NewThinkParser(), tt.text, size)
+				if reasoning != tt.wantReasoning || content != tt.wantContent {
+					t.Fatalf("chunk size %d: got (%q, %q), want (%q, %q)", size, reasoning, content, tt.wantReasoning, tt.wantContent)
+				}
+				wantReasoning, wantContent := SplitThinking(tt.text)
+				if reasoning != wantReasoning || content != wantContent {
+					t.Fatalf("chunk size %d: stream (%q, %q) differs from SplitThinking (%q, %q)", size, reasoning, content, wantReasoning, wantContent)
+				}
+			}
+		})
+	}
+}
+
+func TestThinkParserLookahead(t *testing.T) {
+	parser := &ThinkParser{Lookahead: 4}
+	reasoning, content := feedChunks(parser, "长的思考过程</think>答案", 3)
+	// 超过暂存上限后无法再归为思考过程，但多余的结束标签不能出现在输出中
+	if reasoning != "" || content != "长的思考过程答案" {
+		t.Fatalf("got (%q, %q)", reasoning, content)
+	}
+}
@@ -0,0 +1,43 @@
+package chat
+
+import (
+	"github.com/dfpopp/bigModel"
+)
+
+// EstimateMessageTokens 估算单条消息的 Token 数，包括内容、工具调用和固定的消息开销.
+func EstimateMessageTokens(message ChatCompletionMessage) int {
+	tokens := 4 + bigModel.EstimateTokens(message.Text())
+	for _, toolCall := range message.ToolCalls {
+		tokens += bigModel.EstimateTokens(toolCall.Function.Name) + bigModel.EstimateTokens(toolCall.Function.Arguments)
+	}
+	return tokens
+}
+
+// EstimateRequestTokens 估算请求的输入 Token 数，包括全部消息和工具定义.
+func EstimateRequestTokens(request *ChatCompletionRequest) int {
+	if request == nil {
+		return 0
+	}
+	tokens := 0
+	for _, message := range request.Messages {
+		tokens += EstimateMessageTokens(message)
+	}
+	if len(request.Tools) > 0 {
+		tokens += bigModel.EstimateTokens(bigModel.Json_encode(request.Tools))
+	}
+	return tokens
+}
+
+// MaxOutputTokens 根据模型信息估算请求最多还能输出的 Token 数，未知模型返回 0 和 false.
+// 结果取模型最大输出长度与上下文剩余长度中较小的值.
+func MaxOutputTokens(request *ChatCompletionRequest) (int, bool) {
+	if request == nil {
+		return 0, false
+	}
+	info, ok := bigModel.LookupModel(request.Model)
+	if !ok {
+		return 0, false
+	}
+	remaining := info.ContextWindow - EstimateRequestTokens(request)
+	return max(min(info.MaxOutputTokens, remaining), 0), true
+}
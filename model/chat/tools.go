@@ -0,0 +1,141 @@
+package chat
+
+import (
+	"encoding/json"
+	"github.com/dfpopp/bigModel"
+	"github.com/dfpopp/bigModel/tool/webSearch"
+)
+
+// 工具类型
+const (
+	ToolTypeFunction  = "function"   // 函数调用
+	ToolTypeWebSearch = "web_search" // 网络搜索
+	ToolTypeRetrieval = "retrieval"  // 知识库检索
+	ToolTypeMcp       = "mcp"        // MCP 服务
+)
+
+// WebSearchTool 网络搜索工具的配置.
+type WebSearchTool struct {
+	Enable              *bool  `json:"enable,omitempty"`                // 是否启用搜索，默认启用.
+	SearchEngine        string `json:"search_engine,omitempty"`         // 搜索引擎：search_std、search_pro、search_pro_sogou、search_pro_quark.
+	SearchQuery         string `json:"search_query,omitempty"`          // 强制指定搜索内容，为空时由模型根据对话生成.
+	Count               int    `json:"count,omitempty"`                 // 返回结果的条数，范围1-50，默认10.
+	SearchDomainFilter  string `json:"search_domain_filter,omitempty"`  // 只返回指定域名的结果，例如 www.example.com.
+	SearchRecencyFilter string `json:"search_recency_filter,omitempty"` // 搜索结果的时间范围：oneDay、oneWeek、oneMonth、oneYear、noLimit.
+	ContentSize         string `json:"content_size,omitempty"`          // 网页摘要的字数：medium（摘要）、high（更多上下文）.
+	ResultSequence      string `json:"result_sequence,omitempty"`       // 搜索结果在回复中的位置：before、after.
+	SearchResult        bool   `json:"search_result,omitempty"`         // 是否在响应的 web_search 中返回搜索来源详情.
+	RequireSearch       bool   `json:"require_search,omitempty"`        // 是否强制搜索后再回答.
+	SearchPrompt        string `json:"search_prompt,omitempty"`         // 自定义使用搜索结果的提示词，{search_result} 处替换为搜索结果.
+}
+
+// RetrievalTool 知识库检索工具的配置.
+type RetrievalTool struct {
+	KnowledgeId    string `json:"knowledge_id"`              // 知识库ID (required).
+	PromptTemplate string `json:"prompt_template,omitempty"` // 请求模型时的知识库模板，{{knowledge}} 和 {{question}} 处分别替换为检索结果和用户问题.
+}
+
+// McpServerTool MCP 服务的配置.
+type McpServerTool struct {
+	ServerLabel   string            `json:"server_label"`             // MCP 服务标签，使用平台 MCP 广场的服务时填写服务编码 (required).
+	ServerUrl     string            `json:"server_url,omitempty"`     // 自定义 MCP 服务的地址.
+	TransportType string            `json:"transport_type,omitempty"` // 传输方式：sse、streamable-http，默认 streamable-http.
+	AllowedTools  []string          `json:"allowed_tools,omitempty"`  // 允许调用的工具名称，为空时不限制.
+	Headers       map[string]string `json:"headers,omitempty"`        // 请求 MCP 服务时附加的请求头，例如鉴权信息.
+}
+
+// NewFunctionTool 创建函数调用工具.
+func NewFunctionTool(function Function) Tool {
+	return Tool{Type: ToolTypeFunction, Function: function}
+}
+
+// NewWebSearchTool 创建网络搜索工具.
+func NewWebSearchTool(config WebSearchTool) Tool {
+	return Tool{Type: ToolTypeWebSearch, WebSearch: &config}
+}
+
+// NewRetrievalTool 创建知识库检索工具，promptTemplate 为空时使用平台默认模板.
+func NewRetrievalTool(knowledgeId, promptTemplate string) Tool {
+	return Tool{Type: ToolTypeRetrieval, Retrieval: &RetrievalTool{KnowledgeId: knowledgeId, PromptTemplate: promptTemplate}}
+}
+
+// NewMcpTool 创建 MCP 工具.
+func NewMcpTool(config McpServerTool) Tool {
+	return Tool{Type: ToolTypeMcp, Mcp: &config}
+}
+
+// MarshalJSON 只输出与工具类型对应的配置，Type 为空时根据已设置的配置推断.
+func (t Tool) MarshalJSON() ([]byte, error) {
+	type toolJSON struct {
+		Type      string         `json:"type"`
+		Function  *Function      `json:"function,omitempty"`
+		WebSearch *WebSearchTool `json:"web_search,omitempty"`
+		Retrieval *RetrievalTool `json:"retrieval,omitempty"`
+		Mcp       *McpServerTool `json:"mcp,omitempty"`
+	}
+	out := toolJSON{Type: t.toolType()}
+	switch out.Type {
+	case ToolTypeWebSearch:
+		out.WebSearch = t.WebSearch
+		if out.WebSearch == nil {
+			out.WebSearch = &WebSearchTool{}
+		}
+	case ToolTypeRetrieval:
+		out.Retrieval = t.Retrieval
+	case ToolTypeMcp:
+		out.Mcp = t.Mcp
+	default:
+		out.Function = &t.Function
+	}
+	return json.Marshal(out)
+}
+
+// toolType 返回工具类型，Type 为空时根据已设置的配置推断.
+func (t Tool) toolType() string {
+	switch {
+	case t.Type != "":
+		return t.Type
+	case t.WebSearch != nil:
+		return ToolTypeWebSearch
+	case t.Retrieval != nil:
+		return ToolTypeRetrieval
+	case t.Mcp != nil:
+		return ToolTypeMcp
+	default:
+		return ToolTypeFunction
+	}
+}
+
+// validateTool 校验单个工具的配置.
+func validateTool(v *bigModel.ValidationError, field string, tool Tool) {
+	switch tool.toolType() {
+	case ToolTypeFunction:
+		if !functionNamePattern.MatchString(tool.Function.Name) {
+			v.Add(field+".function.name", "只能包含 a-z、A-Z、0-9、下划线和破折号，最大长度为 64，当前为%q", tool.Function.Name)
+		}
+	case ToolTypeWebSearch:
+		if tool.WebSearch == nil {
+			return
+		}
+		config := tool.WebSearch
+		v.CheckOneOf(field+".web_search.search_engine", config.SearchEngine, webSearch.Engines...)
+		v.CheckOneOf(field+".web_search.search_recency_filter", config.SearchRecencyFilter, webSearch.RecencyFilters...)
+		v.CheckOneOf(field+".web_search.content_size", config.ContentSize, "medium", "high")
+		v.CheckOneOf(field+".web_search.result_sequence", config.ResultSequence, "before", "after")
+		if config.Count < 0 || config.Count > 50 {
+			v.Add(field+".web_search.count", "取值范围为1-50，当前为%d", config.Count)
+		}
+	case ToolTypeRetrieval:
+		if tool.Retrieval == nil || tool.Retrieval.KnowledgeId == "" {
+			v.Add(field+".retrieval.knowledge_id", "不能为空")
+		}
+	case ToolTypeMcp:
+		if tool.Mcp == nil || tool.Mcp.ServerLabel == "" {
+			v.Add(field+".mcp.server_label", "不能为空")
+			return
+		}
+		v.CheckOneOf(field+".mcp.transport_type", tool.Mcp.TransportType, "sse", "streamable-http")
+	default:
+		v.Add(field+".type", "取值应为 function、web_search、retrieval、mcp 之一，当前为%q", tool.Type)
+	}
+}
@@ -0,0 +1,91 @@
+package chat
+
+import (
+	"fmt"
+	"github.com/dfpopp/bigModel"
+)
+
+// MaxTools 单次请求最多支持的工具数量.
+const MaxTools = 128
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+func (r *ChatCompletionRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("model", r.Model)
+	if len(r.Messages) == 0 {
+		v.Add("messages", "不能为空")
+	}
+	onlySystemOrAssistant := len(r.Messages) > 0
+	for i, message := range r.Messages {
+		field := fmt.Sprintf("messages[%d].role", i)
+		switch message.Role {
+		case ChatMessageRoleUser, ChatMessageRoleTool:
+			onlySystemOrAssistant = false
+		case ChatMessageRoleSystem, ChatMessageRoleAssistant:
+		default:
+			v.Add(field, "取值应为 system、user、assistant、tool 之一，当前为%q", message.Role)
+		}
+		if message.Role == ChatMessageRoleTool && message.ToolCallID == "" {
+			v.Add(fmt.Sprintf("messages[%d].tool_call_id", i), "工具消息需要指定对应的工具调用ID")
+		}
+	}
+	if onlySystemOrAssistant {
+		v.Add("messages", "不能只包含系统消息或助手消息")
+	}
+	v.CheckUnitInterval("temperature", float64(r.Temperature))
+	v.CheckUnitInterval("top_p", float64(r.TopP))
+	if r.MaxTokens < 0 {
+		v.Add("max_tokens", "不能小于0")
+	}
+	if len(r.Tools) > MaxTools {
+		v.Add("tools", "最多支持%d个工具，当前为%d", MaxTools, len(r.Tools))
+	}
+	for i, tool := range r.Tools {
+		validateTool(v, fmt.Sprintf("tools[%d]", i), tool)
+	}
+	v.CheckUserId("user_id", r.UserId)
+	if len(r.Stop) > 1 {
+		v.Add("stop", "目前仅支持单个停止词，当前为%d个", len(r.Stop))
+	}
+	if r.ResponseFormat != nil {
+		v.CheckOneOf("response_format.type", r.ResponseFormat.Type, "text", "json_object")
+	}
+	if info, ok := bigModel.LookupModel(r.Model); ok {
+		checkModelCapabilities(v, r, info)
+	}
+	return v.Err()
+}
+
+// checkModelCapabilities 根据模型信息校验上下文长度、最大输出长度以及模型是否支持请求用到的功能.
+func checkModelCapabilities(v *bigModel.ValidationError, r *ChatCompletionRequest, info bigModel.ModelInfo) {
+	if info.MaxOutputTokens > 0 && r.MaxTokens > info.MaxOutputTokens {
+		v.Add("max_tokens", "不能超过模型 %s 的最大输出长度%d", info.Name, info.MaxOutputTokens)
+	}
+	if info.ContextWindow > 0 {
+		if tokens := EstimateRequestTokens(r); tokens+max(r.MaxTokens, 0) > info.ContextWindow {
+			v.Add("messages", "估算输入约%d个Token，加上 max_tokens %d 超过模型 %s 的上下文长度%d", tokens, r.MaxTokens, info.Name, info.ContextWindow)
+		}
+	}
+	if len(r.Tools) > 0 && !info.Tools {
+		v.Add("tools", "模型 %s 不支持工具调用", info.Name)
+	}
+	if r.ResponseFormat != nil && r.ResponseFormat.Type == "json_object" && !info.JSONMode {
+		v.Add("response_format.type", "模型 %s 不支持 json_object 输出", info.Name)
+	}
+	if r.Thinking.Type == "enabled" && !info.Thinking {
+		v.Add("thinking.type", "模型 %s 不支持思维链", info.Name)
+	}
+	if info.Vision {
+		return
+	}
+	for i, message := range r.Messages {
+		if _, ok := message.Content.(string); ok {
+			continue
+		}
+		for j, part := range message.Parts() {
+			if part.Type == ContentTypeImageUrl || part.Type == ContentTypeVideoUrl {
+				v.Add(fmt.Sprintf("messages[%d].content[%d]", i, j), "模型 %s 不支持图片和视频输入", info.Name)
+			}
+		}
+	}
+}
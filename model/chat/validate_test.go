@@ -0,0 +1,61 @@
+package chat
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dfpopp/bigModel"
+)
+
+func TestValidateModelCapabilities(t *testing.T) {
+	user := []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: "你好"}}
+	tests := []struct {
+		name      string
+		request   ChatCompletionRequest
+		wantField string // 为空表示校验通过
+	}{
+		{name: "valid", request: ChatCompletionRequest{Model: "glm-4-flash", Messages: user, MaxTokens: 1024}},
+		{name: "unknown model is not checked", request: ChatCompletionRequest{Model: "my-proxy-model", Messages: user, Thinking: Thinking{Type: "enabled"}}},
+		{name: "max output", request: ChatCompletionRequest{Model: "glm-4-flash", Messages: user, MaxTokens: 5000}, wantField: "max_tokens"},
+		{
+			name:      "context window",
+			request:   ChatCompletionRequest{Model: "glm-4-airx", Messages: []ChatCompletionMessage{{Role: ChatMessageRoleUser, Content: strings.Repeat("长文本", 3000)}}, MaxTokens: 4000},
+			wantField: "messages",
+		},
+		{name: "tools unsupported", request: ChatCompletionRequest{Model: "glm-z1-flash", Messages: user, Tools: []Tool{NewFunctionTool(Function{Name: "f"})}}, wantField: "tools"},
+		{name: "json mode unsupported", request: ChatCompletionRequest{Model: "glm-z1-flash", Messages: user, ResponseFormat: &ResponseFormat{Type: "json_object"}}, wantField: "response_format.type"},
+		{name: "thinking unsupported", request: ChatCompletionRequest{Model: "glm-4-flash", Messages: user, Thinking: Thinking{Type: "enabled"}}, wantField: "thinking.type"},
+		{name: "thinking supported", request: ChatCompletionRequest{Model: "glm-4.5-flash", Messages: user, Thinking: Thinking{Type: "enabled"}}},
+		{
+			name:      "image on text model",
+			request:   ChatCompletionRequest{Model: "glm-4-flash", Messages: []ChatCompletionMessage{UserMessage(TextPart("这是什么"), ImagePart("https://example.com/a.png"))}},
+			wantField: "messages[0].content[1]",
+		},
+		{
+			name:    "image on vision model",
+			request: ChatCompletionRequest{Model: "glm-4v-flash", Messages: []ChatCompletionMessage{UserMessage(TextPart("这是什么"), ImagePart("https://example.com/a.png"))}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.request.Validate()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var validationErr *bigModel.ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("err = %v, want *bigModel.ValidationError", err)
+			}
+			for _, field := range validationErr.Fields {
+				if field.Field == tt.wantField {
+					return
+				}
+			}
+			t.Fatalf("errors %v do not include field %q", validationErr.Fields, tt.wantField)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package embedding
+
+import (
+	"context"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"sync"
+)
+
+// BatchOptions 分批向量化的配置.
+type BatchOptions struct {
+	BatchSize   int // 每批的文本条数，默认且最大为 MaxBatchSize.
+	Concurrency int // 同时发送的请求数，默认为4.
+}
+
+// Embed 将大量文本按每批上限拆分后并发请求，返回按输入顺序合并的向量和累计的 Token 使用统计.
+// 向量按返回的序号放回输入中的位置，不依赖服务端的返回顺序；任意一批失败或向量数量与输入不一致时取消其余请求并返回错误.
+func Embed(ctx context.Context, c *bigModel.Client, request *EmbeddingRequest, opts *BatchOptions) (*EmbeddingResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+	inputs, err := request.inputs()
+	if err != nil {
+		return nil, &bigModel.ValidationError{Fields: []bigModel.FieldError{{Field: "input", Message: err.Error()}}}
+	}
+	if len(inputs) == 0 {
+		return nil, &bigModel.ValidationError{Fields: []bigModel.FieldError{{Field: "input", Message: "不能为空"}}}
+	}
+	batchSize, concurrency := MaxBatchSize, 4
+	if opts != nil {
+		if opts.BatchSize > 0 && opts.BatchSize < MaxBatchSize {
+			batchSize = opts.BatchSize
+		}
+		if opts.Concurrency > 0 {
+			concurrency = opts.Concurrency
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	batches := (len(inputs) + batchSize - 1) / batchSize
+	responses := make([]*EmbeddingResponse, batches)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, concurrency)
+	for i := 0; i < batches; i++ {
+		start := i * batchSize
+		end := min(start+batchSize, len(inputs))
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i, start, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := PostRequest(c, ctx, &EmbeddingRequest{
+				Model:      request.Model,
+				Input:      inputs[start:end],
+				Dimensions: request.Dimensions,
+			})
+			if err == nil {
+				err = checkBatch(resp, end-start)
+			}
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("第%d批(第%d-%d条)失败: %w", i+1, start, end-1, err)
+					cancel()
+				})
+				return
+			}
+			responses[i] = resp
+		}(i, start, end)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	result := &EmbeddingResponse{Model: request.Model, Object: "list", Data: make([]Embedding, len(inputs))}
+	for i, resp := range responses {
+		offset := i * batchSize
+		for _, item := range resp.Data {
+			item.Index += offset
+			result.Data[item.Index] = item
+		}
+		if resp.Model != "" {
+			result.Model = resp.Model
+		}
+		result.Usage.PromptTokens += resp.Usage.PromptTokens
+		result.Usage.CompletionTokens += resp.Usage.CompletionTokens
+		result.Usage.TotalTokens += resp.Usage.TotalTokens
+	}
+	return result, nil
+}
+
+// checkBatch 校验一批结果的向量数量与输入一致，且每个序号都在范围内并且不重复.
+func checkBatch(resp *EmbeddingResponse, count int) error {
+	if len(resp.Data) != count {
+		return fmt.Errorf("返回了%d个向量，与输入的%d条文本不一致", len(resp.Data), count)
+	}
+	seen := make([]bool, count)
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= count || seen[item.Index] {
+			return fmt.Errorf("返回的向量序号%d无效", item.Index)
+		}
+		seen[item.Index] = true
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/dfpopp/bigModel"
+)
+
+// newEmbeddingServer 返回一个以输入文本的数值作为向量的测试服务，结果按倒序返回；drop 为 true 时少返回一个向量.
+func newEmbeddingServer(t *testing.T, drop bool) *bigModel.Client {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []string `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		resp := EmbeddingResponse{Model: "embedding-3", Object: "list"}
+		for i := len(req.Input) - 1; i >= 0; i-- {
+			n, _ := strconv.ParseFloat(req.Input[i], 64)
+			resp.Data = append(resp.Data, Embedding{Object: "embedding", Index: i, Embedding: []float64{n}})
+		}
+		if drop {
+			resp.Data = resp.Data[1:]
+		}
+		resp.Usage.TotalTokens = len(req.Input)
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(srv.Close)
+	c, err := bigModel.NewClientWithOptions("id.secret", bigModel.WithBaseURL(srv.URL+"/"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestEmbedPreservesOrder(t *testing.T) {
+	inputs := make([]string, 150)
+	for i := range inputs {
+		inputs[i] = strconv.Itoa(i)
+	}
+	c := newEmbeddingServer(t, false)
+	resp, err := Embed(context.Background(), c, &EmbeddingRequest{Model: "embedding-3", Input: inputs}, &BatchOptions{BatchSize: 20, Concurrency: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != len(inputs) {
+		t.Fatalf("len(data) = %d, want %d", len(resp.Data), len(inputs))
+	}
+	for i, item := range resp.Data {
+		if item.Index != i || item.Embedding[0] != float64(i) {
+			t.Fatalf("data[%d] = %+v", i, item)
+		}
+	}
+	if resp.Usage.TotalTokens != len(inputs) {
+		t.Fatalf("total tokens = %d, want %d", resp.Usage.TotalTokens, len(inputs))
+	}
+}
+
+func TestEmbedCountMismatch(t *testing.T) {
+	c := newEmbeddingServer(t, true)
+	if _, err := Embed(context.Background(), c, &EmbeddingRequest{Model: "embedding-3", Input: []string{"1", "2", "3"}}, nil); err == nil {
+		t.Fatal("expected error for missing vectors")
+	}
+}
@@ -0,0 +1,153 @@
+package embedding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"io"
+	"net/http"
+)
+
+// EmbeddingsPath 文本向量化接口的默认路径
+const EmbeddingsPath = "paas/v4/embeddings"
+
+// MaxBatchSize 单次请求最多支持的文本条数.
+const MaxBatchSize = 64
+
+// EmbeddingRequest 文本向量化请求.
+type EmbeddingRequest struct {
+	Model      string `json:"model"`                // 模型代码，例如 embedding-3、embedding-2 (required).
+	Input      any    `json:"input"`                // 需要向量化的文本，支持 string 或 []string，数组最多64条 (required).
+	Dimensions int    `json:"dimensions,omitempty"` // 输出向量的维度，仅 embedding-3 支持，可选 256、512、1024、2048，默认2048.
+}
+
+// Embedding 单条文本的向量.
+type Embedding struct {
+	Object    string    `json:"object"`    // 固定为 embedding.
+	Index     int       `json:"index"`     // 文本在输入中的序号.
+	Embedding []float64 `json:"embedding"` // 向量.
+}
+
+// Usage 调用结束时返回的 Token 使用统计.
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`     // 输入的 Token 数量.
+	CompletionTokens int `json:"completion_tokens"` // 输出的 Token 数量.
+	TotalTokens      int `json:"total_tokens"`      // Token 总数.
+}
+
+// EmbeddingResponse 文本向量化的结果.
+type EmbeddingResponse struct {
+	Model  string      `json:"model"`  // 模型名称.
+	Object string      `json:"object"` // 固定为 list.
+	Data   []Embedding `json:"data"`   // 向量列表，按输入顺序排列.
+	Usage  Usage       `json:"usage"`  // Token 使用统计.
+}
+
+// Vectors 按输入顺序返回全部向量.
+func (r *EmbeddingResponse) Vectors() [][]float64 {
+	vectors := make([][]float64, len(r.Data))
+	for i, item := range r.Data {
+		vectors[i] = item.Embedding
+	}
+	return vectors
+}
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+func (r *EmbeddingRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("model", r.Model)
+	inputs, err := r.inputs()
+	switch {
+	case err != nil:
+		v.Add("input", "%v", err)
+	case len(inputs) == 0:
+		v.Add("input", "不能为空")
+	case len(inputs) > MaxBatchSize:
+		v.Add("input", "最多支持%d条文本，当前为%d条，可使用 Embed 自动分批", MaxBatchSize, len(inputs))
+	}
+	for i, input := range inputs {
+		if input == "" {
+			v.Add(fmt.Sprintf("input[%d]", i), "不能为空")
+		}
+	}
+	switch r.Dimensions {
+	case 0, 256, 512, 1024, 2048:
+	default:
+		v.Add("dimensions", "取值应为 256、512、1024、2048 之一，当前为%d", r.Dimensions)
+	}
+	return v.Err()
+}
+
+// inputs 将 Input 统一转换为字符串列表.
+func (r *EmbeddingRequest) inputs() ([]string, error) {
+	switch input := r.Input.(type) {
+	case string:
+		return []string{input}, nil
+	case []string:
+		return input, nil
+	case []any:
+		inputs := make([]string, len(input))
+		for i, item := range input {
+			text, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("第%d条不是字符串", i)
+			}
+			inputs[i] = text
+		}
+		return inputs, nil
+	case nil:
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("仅支持 string 或 []string，当前为 %T", r.Input)
+	}
+}
+
+// PostRequest 发送单次文本向量化请求，输入超过64条时请使用 Embed.
+func PostRequest(c *bigModel.Client, ctx context.Context, request *EmbeddingRequest) (*EmbeddingResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+	if err := c.Validate(request); err != nil {
+		return nil, err
+	}
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error getting timeout context: %w", err)
+	}
+	defer tcancel()
+	req, err := bigModel.NewJSONRequest(EmbeddingsPath, request)
+	if err != nil {
+		return nil, err
+	}
+	req.Model = request.Model
+	resp, err := c.PostRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, bigModel.HandleError(resp)
+	}
+	respData, err := HandleEmbeddingResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	c.RecordUsage(request.Model, respData.Usage.TotalTokens)
+	return respData, nil
+}
+
+// HandleEmbeddingResponse 解析来自文本向量化接口的响应.
+func HandleEmbeddingResponse(resp *http.Response) (*EmbeddingResponse, error) {
+	body, err := io.ReadAll(resp.Body) //一次性全部读取响应.
+	if err != nil {
+		return nil, fmt.Errorf("无法读取响应正文: %w", err)
+	}
+	var parsedResponse EmbeddingResponse
+	if err := json.Unmarshal(body, &parsedResponse); err != nil {
+		return nil, bigModel.HandleAPIError(body)
+	}
+	if len(parsedResponse.Data) == 0 {
+		return nil, fmt.Errorf("无效响应: 没有向量数据")
+	}
+	return &parsedResponse, nil
+}
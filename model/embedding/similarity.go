@@ -0,0 +1,42 @@
+package embedding
+
+import (
+	"fmt"
+	"math"
+)
+
+// CosineSimilarity 计算两个向量的余弦相似度，取值范围 [-1, 1]；任一向量为零向量时返回0.
+func CosineSimilarity(a, b []float64) (float64, error) {
+	if len(a) != len(b) {
+		return 0, fmt.Errorf("向量维度不一致: %d 和 %d", len(a), len(b))
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0, nil
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB)), nil
+}
+
+// Normalize 返回向量的单位向量，零向量返回其副本.
+// 归一化后的向量可以直接用点积代替余弦相似度.
+func Normalize(v []float64) []float64 {
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	result := make([]float64, len(v))
+	if norm == 0 {
+		copy(result, v)
+		return result
+	}
+	norm = math.Sqrt(norm)
+	for i, x := range v {
+		result[i] = x / norm
+	}
+	return result
+}
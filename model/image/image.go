@@ -7,6 +7,9 @@ import (
 	"github.com/dfpopp/bigModel/tool/moderations"
 )
 
+// GenerationsPath 图片生成接口的默认路径
+const GenerationsPath = "paas/v4/images/generations"
+
 // ImageResult 图片生成结果
 type ImageResult struct {
 	Url string `json:"url"` // 图片链接。图片的临时链接有效期为30天，请及时转存图片.
@@ -34,19 +37,20 @@ func PostRequest(c *bigModel.Client, ctx context.Context, request *ImageCompleti
 	if request == nil {
 		return nil, fmt.Errorf("请求不能为空")
 	}
-	if c.Path == "" {
-		c.Path = "paas/v4/images/generations"
+	if err := c.Validate(request); err != nil {
+		return nil, err
 	}
 	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
 	defer tcancel()
-	err = bigModel.SetBodyFromStruct(request)(c)
+	req, err := bigModel.NewJSONRequest(GenerationsPath, request)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.PostRequest(ctx)
+	req.Model = request.Model
+	resp, err := c.PostRequest(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
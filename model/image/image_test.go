@@ -0,0 +1,68 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dfpopp/bigModel"
+)
+
+func TestPostRequestErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		wantKind error
+		wantCode int
+		wrapped  bool // 错误是否经过 "请求失败: %w" 包装
+	}{
+		{
+			name: "api error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"code":1211,"msg":"模型不存在"}`))
+			},
+			wantKind: bigModel.ErrModelNotFound,
+			wantCode: 1211,
+		},
+		{
+			name: "timeout",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				_, _ = io.Copy(io.Discard, r.Body) // 读完请求体后服务端才能感知客户端断开
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			wantKind: bigModel.ErrTimeout,
+			wrapped:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(tt.handler)
+			defer srv.Close()
+			c, err := bigModel.NewClientWithOptions("id.secret", bigModel.WithBaseURL(srv.URL+"/"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			c.Timeout = 50 * time.Millisecond
+			_, err = PostRequest(c, context.Background(), &ImageCompletionRequest{Model: "cogview-3-flash", Prompt: "一只猫"})
+			var apiErr *bigModel.APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("PostRequest() error = %v, want *bigModel.APIError", err)
+			}
+			if !errors.Is(err, tt.wantKind) || apiErr.APICode != tt.wantCode {
+				t.Fatalf("PostRequest() error = %+v, want kind %v code %d", apiErr, tt.wantKind, tt.wantCode)
+			}
+			if tt.wrapped != strings.HasPrefix(err.Error(), "请求失败: ") {
+				t.Fatalf("PostRequest() error = %q, wrapped = %v", err, tt.wrapped)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package image
+
+import (
+	"github.com/dfpopp/bigModel"
+	"strconv"
+	"strings"
+)
+
+// 自定义图片尺寸的限制
+const (
+	MinSize      = 512     // 宽高的最小值
+	MaxSize      = 2048    // 宽高的最大值
+	SizeMultiple = 16      // 宽高需要被整除的值
+	MaxPixels    = 1 << 21 // 最大像素数
+)
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+func (r *ImageCompletionRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("model", r.Model)
+	v.CheckRequired("prompt", r.Prompt)
+	v.CheckOneOf("quality", r.Quality, "hd", "standard")
+	if r.Size != "" {
+		checkSize(v, r.Size)
+	}
+	v.CheckUserId("user_id", r.UserId)
+	return v.Err()
+}
+
+// checkSize 校验图片尺寸，格式为 "宽x高"，宽高均需在512到2048之间，被16整除，且像素数不超过2^21.
+func checkSize(v *bigModel.ValidationError, size string) {
+	w, h, ok := strings.Cut(strings.ToLower(size), "x")
+	width, werr := strconv.Atoi(strings.TrimSpace(w))
+	height, herr := strconv.Atoi(strings.TrimSpace(h))
+	if !ok || werr != nil || herr != nil {
+		v.Add("size", "格式应为 宽x高，例如 1024x1024，当前为%q", size)
+		return
+	}
+	for _, side := range []int{width, height} {
+		if side < MinSize || side > MaxSize {
+			v.Add("size", "宽高需在%d到%d之间，当前为%s", MinSize, MaxSize, size)
+			return
+		}
+		if side%SizeMultiple != 0 {
+			v.Add("size", "宽高需被%d整除，当前为%s", SizeMultiple, size)
+			return
+		}
+	}
+	if width*height > MaxPixels {
+		v.Add("size", "像素数不能超过%d，当前为%d", MaxPixels, width*height)
+	}
+}
@@ -0,0 +1,159 @@
+package rerank
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// RerankPath 文本重排序接口的默认路径
+const RerankPath = "paas/v4/rerank"
+
+// DefaultModel 默认的重排序模型.
+const DefaultModel = "rerank"
+
+// 文本重排序接口的限制
+const (
+	MaxDocuments   = 128  // 单次请求最多支持的候选文本数量.
+	MaxQueryLength = 4096 // 查询文本的最大字符数.
+	MaxDocLength   = 4096 // 单条候选文本的最大字符数.
+)
+
+// RerankRequest 文本重排序请求.
+type RerankRequest struct {
+	Model           string   `json:"model"`                       // 模型代码，默认 rerank (required).
+	Query           string   `json:"query"`                       // 查询文本，最多4096个字符 (required).
+	Documents       []string `json:"documents"`                   // 需要排序的候选文本，最多128条，每条最多4096个字符 (required).
+	TopN            int      `json:"top_n,omitempty"`             // 返回得分最高的前 N 条，为0时返回全部.
+	ReturnDocuments bool     `json:"return_documents,omitempty"`  // 是否在结果中返回原文.
+	ReturnRawScores bool     `json:"return_raw_scores,omitempty"` // 是否返回未归一化的原始得分.
+	RequestId       string   `json:"request_id,omitempty"`        // 由用户端传递，需要唯一；用于区分每次请求的唯一标识符。如果用户端未提供，平台将默认生成.
+	UserId          string   `json:"user_id,omitempty"`           // 终端用户的唯一ID，长度为6-128个字符.
+}
+
+// Result 单条候选文本的排序结果.
+type Result struct {
+	Index          int     `json:"index"`              // 候选文本在 Documents 中的序号.
+	RelevanceScore float64 `json:"relevance_score"`    // 与查询的相关性得分，越大越相关.
+	Document       string  `json:"document,omitempty"` // 候选文本原文，ReturnDocuments 为 true 时返回.
+}
+
+// Usage 调用结束时返回的 Token 使用统计.
+type Usage struct {
+	PromptTokens int `json:"prompt_tokens"` // 输入的 Token 数量.
+	TotalTokens  int `json:"total_tokens"`  // Token 总数.
+}
+
+// RerankResponse 文本重排序的结果.
+type RerankResponse struct {
+	Id        string   `json:"id"`         // 任务ID.
+	Created   int64    `json:"created"`    // 请求创建时间，是以秒为单位的 Unix 时间戳.
+	RequestId string   `json:"request_id"` // 请求ID.
+	Results   []Result `json:"results"`    // 排序结果，按相关性得分从高到低排列.
+	Usage     Usage    `json:"usage"`      // Token 使用统计.
+}
+
+// Indices 按相关性从高到低返回候选文本的原始序号.
+func (r *RerankResponse) Indices() []int {
+	indices := make([]int, len(r.Results))
+	for i, result := range r.Results {
+		indices[i] = result.Index
+	}
+	return indices
+}
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+func (r *RerankRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("model", r.Model)
+	v.CheckRequired("query", r.Query)
+	v.CheckMaxLength("query", r.Query, MaxQueryLength)
+	switch {
+	case len(r.Documents) == 0:
+		v.Add("documents", "不能为空")
+	case len(r.Documents) > MaxDocuments:
+		v.Add("documents", "最多支持%d条，当前为%d条", MaxDocuments, len(r.Documents))
+	}
+	for i, doc := range r.Documents {
+		field := fmt.Sprintf("documents[%d]", i)
+		v.CheckRequired(field, doc)
+		v.CheckMaxLength(field, doc, MaxDocLength)
+	}
+	if r.TopN < 0 {
+		v.Add("top_n", "不能小于0，当前为%d", r.TopN)
+	}
+	v.CheckUserId("user_id", r.UserId)
+	return v.Err()
+}
+
+// PostRequest 发送文本重排序请求，结果按相关性得分从高到低排列并保留原始序号.
+func PostRequest(c *bigModel.Client, ctx context.Context, request *RerankRequest) (*RerankResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+	if request.Model == "" {
+		// 在副本上设置默认模型，不修改调用方的请求
+		withDefault := *request
+		withDefault.Model = DefaultModel
+		request = &withDefault
+	}
+	if err := c.Validate(request); err != nil {
+		return nil, err
+	}
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error getting timeout context: %w", err)
+	}
+	defer tcancel()
+	req, err := bigModel.NewJSONRequest(RerankPath, request)
+	if err != nil {
+		return nil, err
+	}
+	req.Model = request.Model
+	resp, err := c.PostRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, bigModel.HandleError(resp)
+	}
+	respData, err := HandleRerankResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	for i := range respData.Results {
+		result := &respData.Results[i]
+		if request.ReturnDocuments && result.Document == "" && result.Index >= 0 && result.Index < len(request.Documents) {
+			result.Document = request.Documents[result.Index]
+		}
+	}
+	c.RecordUsage(request.Model, respData.Usage.TotalTokens)
+	return respData, nil
+}
+
+// HandleRerankResponse 解析来自文本重排序接口的响应，结果按相关性得分从高到低排序，得分相同时按原始序号排序.
+func HandleRerankResponse(resp *http.Response) (*RerankResponse, error) {
+	body, err := io.ReadAll(resp.Body) //一次性全部读取响应.
+	if err != nil {
+		return nil, fmt.Errorf("无法读取响应正文: %w", err)
+	}
+	var parsedResponse RerankResponse
+	if err := json.Unmarshal(body, &parsedResponse); err != nil {
+		return nil, bigModel.HandleAPIError(body)
+	}
+	if parsedResponse.Results == nil {
+		return nil, bigModel.HandleAPIError(body)
+	}
+	sort.SliceStable(parsedResponse.Results, func(i, j int) bool {
+		a, b := parsedResponse.Results[i], parsedResponse.Results[j]
+		if a.RelevanceScore != b.RelevanceScore {
+			return a.RelevanceScore > b.RelevanceScore
+		}
+		return a.Index < b.Index
+	})
+	return &parsedResponse, nil
+}
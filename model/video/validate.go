@@ -0,0 +1,46 @@
+package video
+
+import (
+	"github.com/dfpopp/bigModel"
+)
+
+// MaxPromptLength 视频文本描述的最大字符数.
+const MaxPromptLength = 1500
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+func (r *VideoCompletionRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("model", r.Model)
+	if r.Prompt == "" && isEmptyImageUrl(r.ImageUrl) {
+		v.Add("prompt", "prompt 和 image_url 至少需要传入一个")
+	}
+	v.CheckMaxLength("prompt", r.Prompt, MaxPromptLength)
+	v.CheckOneOf("quality", r.Quality, "speed", "quality")
+	v.CheckOneOf("style", r.Style, "general", "anime")
+	v.CheckOneOf("aspect_ratio", r.AspectRatio, "16:9", "9:16", "1:1")
+	v.CheckOneOf("movement_amplitude", r.MovementAmplitude, "auto", "small", "medium", "large")
+	if r.Fps != 0 && r.Fps != 30 && r.Fps != 60 {
+		v.Add("fps", "取值应为 30 或 60，当前为%d", r.Fps)
+	}
+	if r.Duration != 0 && r.Duration != 5 && r.Duration != 10 {
+		v.Add("duration", "取值应为 5 或 10，当前为%d", r.Duration)
+	}
+	v.CheckUserId("user_id", r.UserId)
+	return v.Err()
+}
+
+// isEmptyImageUrl 判断 image_url 是否未传入，支持单个地址或地址列表.
+func isEmptyImageUrl(imageUrl any) bool {
+	switch u := imageUrl.(type) {
+	case nil:
+		return true
+	case string:
+		return u == ""
+	case []string:
+		return len(u) == 0
+	case []any:
+		return len(u) == 0
+	default:
+		return false
+	}
+}
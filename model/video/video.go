@@ -6,6 +6,9 @@ import (
 	"github.com/dfpopp/bigModel"
 )
 
+// GenerationsPath 视频生成接口的默认路径
+const GenerationsPath = "paas/v4/videos/generations"
+
 // VideoResult 视频生成结果
 type VideoResult struct {
 	Url           string `json:"url"`             // 视频链接.
@@ -51,19 +54,20 @@ func AsyncRequest(c *bigModel.Client, ctx context.Context, request *VideoComplet
 	if request == nil {
 		return nil, fmt.Errorf("请求不能为空")
 	}
-	if c.Path == "" {
-		c.Path = "paas/v4/videos/generations"
+	if err := c.Validate(request); err != nil {
+		return nil, err
 	}
 	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
 	if err != nil {
 		return nil, fmt.Errorf("error getting timeout context: %w", err)
 	}
 	defer tcancel()
-	err = bigModel.SetBodyFromStruct(request)(c)
+	req, err := bigModel.NewJSONRequest(GenerationsPath, request)
 	if err != nil {
 		return nil, err
 	}
-	resp, err := c.PostRequest(ctx)
+	req.Model = request.Model
+	resp, err := c.PostRequest(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("请求失败: %w", err)
 	}
@@ -0,0 +1,129 @@
+package bigModel
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ModelInfo 模型的上下文长度、最大输出长度和支持的功能.
+type ModelInfo struct {
+	Name            string // 模型代码.
+	ContextWindow   int    // 上下文长度，单位 Token.
+	MaxOutputTokens int    // 最大输出长度，单位 Token.
+	Thinking        bool   // 是否支持思维链.
+	Tools           bool   // 是否支持函数调用.
+	JSONMode        bool   // 是否支持 response_format 为 json_object.
+	Vision          bool   // 是否支持图片、视频等视觉输入.
+}
+
+var (
+	modelsMu sync.RWMutex
+	// models 内置的模型信息，数据来自官方文档，可以通过 RegisterModel 覆盖或补充
+	models = map[string]ModelInfo{
+		"glm-4.6":                  {Name: "glm-4.6", ContextWindow: 200000, MaxOutputTokens: 128000, Thinking: true, Tools: true, JSONMode: true},
+		"glm-4.5":                  {Name: "glm-4.5", ContextWindow: 128000, MaxOutputTokens: 96000, Thinking: true, Tools: true, JSONMode: true},
+		"glm-4.5-x":                {Name: "glm-4.5-x", ContextWindow: 128000, MaxOutputTokens: 96000, Thinking: true, Tools: true, JSONMode: true},
+		"glm-4.5-air":              {Name: "glm-4.5-air", ContextWindow: 128000, MaxOutputTokens: 96000, Thinking: true, Tools: true, JSONMode: true},
+		"glm-4.5-airx":             {Name: "glm-4.5-airx", ContextWindow: 128000, MaxOutputTokens: 96000, Thinking: true, Tools: true, JSONMode: true},
+		"glm-4.5-flash":            {Name: "glm-4.5-flash", ContextWindow: 128000, MaxOutputTokens: 96000, Thinking: true, Tools: true, JSONMode: true},
+		"glm-4.5v":                 {Name: "glm-4.5v", ContextWindow: 64000, MaxOutputTokens: 16000, Thinking: true, Vision: true},
+		"glm-4-plus":               {Name: "glm-4-plus", ContextWindow: 128000, MaxOutputTokens: 4000, Tools: true, JSONMode: true},
+		"glm-4-air-250414":         {Name: "glm-4-air-250414", ContextWindow: 128000, MaxOutputTokens: 16000, Tools: true, JSONMode: true},
+		"glm-4-airx":               {Name: "glm-4-airx", ContextWindow: 8000, MaxOutputTokens: 4000, Tools: true, JSONMode: true},
+		"glm-4-long":               {Name: "glm-4-long", ContextWindow: 1000000, MaxOutputTokens: 4000, Tools: true, JSONMode: true},
+		"glm-4-flashx-250414":      {Name: "glm-4-flashx-250414", ContextWindow: 128000, MaxOutputTokens: 16000, Tools: true, JSONMode: true},
+		"glm-4-flash-250414":       {Name: "glm-4-flash-250414", ContextWindow: 128000, MaxOutputTokens: 16000, Tools: true, JSONMode: true},
+		"glm-4-flash":              {Name: "glm-4-flash", ContextWindow: 128000, MaxOutputTokens: 4000, Tools: true, JSONMode: true},
+		"glm-z1-air":               {Name: "glm-z1-air", ContextWindow: 128000, MaxOutputTokens: 32000, Thinking: true},
+		"glm-z1-airx":              {Name: "glm-z1-airx", ContextWindow: 32000, MaxOutputTokens: 30000, Thinking: true},
+		"glm-z1-flashx":            {Name: "glm-z1-flashx", ContextWindow: 128000, MaxOutputTokens: 32000, Thinking: true},
+		"glm-z1-flash":             {Name: "glm-z1-flash", ContextWindow: 128000, MaxOutputTokens: 32000, Thinking: true},
+		"glm-4.1v-thinking-flashx": {Name: "glm-4.1v-thinking-flashx", ContextWindow: 64000, MaxOutputTokens: 16000, Thinking: true, Vision: true},
+		"glm-4.1v-thinking-flash":  {Name: "glm-4.1v-thinking-flash", ContextWindow: 64000, MaxOutputTokens: 16000, Thinking: true, Vision: true},
+		"glm-4v-plus-0111":         {Name: "glm-4v-plus-0111", ContextWindow: 16000, MaxOutputTokens: 8000, Vision: true},
+		"glm-4v-flash":             {Name: "glm-4v-flash", ContextWindow: 16000, MaxOutputTokens: 1000, Vision: true},
+		"glm-4-voice":              {Name: "glm-4-voice", ContextWindow: 8000, MaxOutputTokens: 4000},
+	}
+)
+
+// RegisterModel 注册或覆盖模型信息，名称不区分大小写.
+func RegisterModel(info ModelInfo) {
+	info.Name = strings.ToLower(info.Name)
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+	models[info.Name] = info
+}
+
+// LookupModel 查询模型信息，名称不区分大小写；没有完全匹配时使用最长的已注册前缀匹配，例如带日期后缀的版本.
+func LookupModel(name string) (ModelInfo, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+	if info, ok := models[name]; ok {
+		return info, true
+	}
+	var (
+		best  ModelInfo
+		found bool
+	)
+	for key, info := range models {
+		if strings.HasPrefix(name, key+"-") && len(key) > len(best.Name) {
+			best, found = info, true
+		}
+	}
+	return best, found
+}
+
+// Models 返回全部已注册的模型信息，按名称排序.
+func Models() []ModelInfo {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+	list := make([]ModelInfo, 0, len(models))
+	for _, info := range models {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// EstimateTokens 离线估算文本的 Token 数，适用于中英文混合文本，结果偏保守.
+// 中日韩文字约1.5个字符1个Token，英文单词约4个字母1个Token，连续数字约3位1个Token，标点和其他符号各1个Token.
+func EstimateTokens(text string) int {
+	var (
+		cjk     int
+		tokens  int
+		letters int
+		digits  int
+	)
+	flush := func() {
+		tokens += (letters + 3) / 4
+		tokens += (digits + 2) / 3
+		letters, digits = 0, 0
+	}
+	for _, r := range text {
+		switch {
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			flush()
+			cjk++
+		case unicode.IsLetter(r):
+			if digits > 0 {
+				flush()
+			}
+			letters++
+		case unicode.IsDigit(r):
+			if letters > 0 {
+				flush()
+			}
+			digits++
+		case unicode.IsSpace(r):
+			flush()
+		default:
+			flush()
+			tokens++
+		}
+	}
+	flush()
+	return tokens + (cjk*2+2)/3
+}
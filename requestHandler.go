@@ -11,7 +11,6 @@ import (
 	"net/http"
 	"os"
 	"path"
-	"strings"
 	"time"
 )
 
@@ -24,14 +23,31 @@ type HTTPDoer interface {
 }
 
 // Client 与API接口请求的主要结构体.
+// Client 创建后只保存不可变的配置，每次调用的请求数据通过 Request 传入，因此同一个 Client 可以被多个协程并发使用.
 type Client struct {
-	AuthToken   string        // API请求的验证令牌APIKEY
-	BaseURL     string        // 智谱API接口基础请求地址
-	Timeout     time.Duration // 客户端请求超时时间
-	Path        string        // API请求的路径。默认为 "chat/completions"
-	Body        []byte
-	ContentType string   //form表单提交的需要该参数
-	HTTPClient  HTTPDoer // HTTP客户端发送请求后获得的响应
+	AuthToken         string        // API请求的验证令牌APIKEY
+	BaseURL           string        // 智谱API接口基础请求地址
+	Timeout           time.Duration // 客户端请求超时时间
+	Path              string        // 对话补全接口的路径，用于代理等场景，为空时使用默认路径；其他接口始终使用各自的默认路径
+	HTTPClient        HTTPDoer      // HTTP客户端发送请求后获得的响应
+	RetryPolicy       *RetryPolicy  // 请求失败后的重试策略，为空时不重试
+	Limiter           *Limiter      // 客户端限流器，为空时不限流
+	AuthMode          AuthMode      // 鉴权方式，默认直接使用APIKEY
+	JWTTTL            time.Duration // JWT令牌的有效期，仅在 AuthModeJWT 下生效，默认30分钟
+	Middlewares       []Middleware  // 包裹 HTTPClient 的中间件，按顺序由外向内执行
+	StreamIdleTimeout time.Duration // 流式响应两次收到数据之间允许的最长间隔，0表示不限制
+	SkipValidation    bool          // 为 true 时发送请求前不校验请求参数
+	jwt               jwtCache      // 已签发的JWT令牌缓存
+}
+
+// Request 单次API调用的请求数据，每次调用单独创建，不会写回 Client.
+type Request struct {
+	Method      string      // 请求方式，为空时由调用的方法决定
+	Path        string      // API请求的路径 (required)
+	Header      http.Header // 额外的请求头
+	Body        []byte      // 请求体
+	ContentType string      // 请求体类型，为空时默认为 application/json
+	Model       string      // 请求调用的模型名称
 }
 
 // Option 配置客户端实例
@@ -50,7 +66,6 @@ func NewClientWithOptions(authToken string, opts ...Option) (*Client, error) {
 		AuthToken: authToken,
 		BaseURL:   BaseURL,
 		Timeout:   5 * time.Minute,
-		Path:      "paas/v4/chat/completions",
 	}
 	for _, opt := range opts {
 		if err := opt(client); err != nil {
@@ -91,11 +106,8 @@ func WithTimeoutString(s string) Option {
 	}
 }
 
-// WithPath 设置API请求的路径。如果未设置，则默认为“聊天/完成”.
+// WithPath 设置对话补全接口的路径。如果未设置，则使用默认路径 paas/v4/chat/completions；不影响图像、音频等其他接口.
 func WithPath(path string) Option {
-	if path == "" {
-		path = "paas/v4/chat/completions"
-	}
 	return func(c *Client) error {
 		c.Path = path
 		return nil
@@ -109,52 +121,62 @@ func WithHTTPClient(httpclient HTTPDoer) Option {
 		return nil
 	}
 }
-func SetBodyFromStruct(data interface{}) Option {
-	return func(c *Client) error {
-		body, err := json.Marshal(data)
-		if err != nil {
-			return errors.New(fmt.Sprintf("错误的json: %v", err))
-		}
-		c.Body = body
-		return nil
+
+// NewJSONRequest 将结构体编码为JSON请求体，创建一个新的请求.
+func NewJSONRequest(reqPath string, data interface{}) (*Request, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("错误的json: %v", err))
 	}
+	return &Request{
+		Path:        reqPath,
+		Body:        body,
+		ContentType: "application/json",
+	}, nil
 }
-func SetBodyToForm(data map[string]string, fileFieldName string) Option {
-	return func(c *Client) error {
-		var body bytes.Buffer
-		writer := multipart.NewWriter(&body)
-		for field, value := range data {
-			if field != fileFieldName {
-				err := writer.WriteField(field, value)
-				if err != nil {
-					return err
-				}
+
+// NewFormRequest 将表单字段和文件编码为multipart请求体，创建一个新的请求.
+func NewFormRequest(reqPath string, data map[string]string, fileFieldName string) (*Request, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for field, value := range data {
+		if field != fileFieldName {
+			err := writer.WriteField(field, value)
+			if err != nil {
+				return nil, err
 			}
 		}
-		// 创建表单字段
-		part, err := writer.CreateFormFile(fileFieldName, path.Base(data[fileFieldName]))
-		if err != nil {
-			return err
-		}
-		// 将文件内容复制到表单字段中
-		file, err := os.Open(data[fileFieldName])
-		if err != nil {
-			return err
-		}
-		defer file.Close() // 修复：漏了关闭文件，可能导致资源泄漏
-		_, err = io.Copy(part, file)
-		if err != nil {
-			return err
-		}
-		// 关闭writer，确保所有缓冲区的数据都被刷新到底层的io.Writer中
-		err = writer.Close()
-		if err != nil {
-			fmt.Println(err.Error())
-		}
-		c.Body = body.Bytes()
-		c.ContentType = writer.FormDataContentType()
-		return nil
 	}
+	// 创建表单字段
+	part, err := writer.CreateFormFile(fileFieldName, path.Base(data[fileFieldName]))
+	if err != nil {
+		return nil, err
+	}
+	// 将文件内容复制到表单字段中
+	file, err := os.Open(data[fileFieldName])
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close() // 修复：漏了关闭文件，可能导致资源泄漏
+	_, err = io.Copy(part, file)
+	if err != nil {
+		return nil, err
+	}
+	// 关闭writer，确保所有缓冲区的数据都被刷新到底层的io.Writer中
+	err = writer.Close()
+	if err != nil {
+		return nil, err
+	}
+	return &Request{
+		Path:        reqPath,
+		Body:        body.Bytes(),
+		ContentType: writer.FormDataContentType(),
+	}, nil
+}
+
+// BodyReader 返回请求体的读取器，每次调用都会返回一个新的读取器.
+func (r *Request) BodyReader() io.Reader {
+	return bytes.NewReader(r.Body)
 }
 
 // GetTimeoutContext 创建具有超时的上下文.
@@ -171,110 +193,105 @@ func GetTimeoutContext(ctx context.Context, timeout time.Duration) (context.Cont
 }
 
 // PostRequest 构造一个post方式的HTTP请求.
-func (c *Client) PostRequest(ctx context.Context) (*http.Response, error) {
-	if c.BaseURL == "" || c.Path == "" {
-		return nil, fmt.Errorf("请求的API接口地址或路径未设置")
-	}
-	url := fmt.Sprintf("%s%s", c.BaseURL, c.Path)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(c.Body))
-	if err != nil {
-		return nil, fmt.Errorf("创建请求体错误: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	req.Header.Set("Content-Type", "application/json")
-	return c.handleRequest(req)
+func (c *Client) PostRequest(ctx context.Context, request *Request) (*http.Response, error) {
+	return c.handleRequest(ctx, http.MethodPost, request, false)
 }
 
 // PostStreamRequest 构造流式响应的post方式HTTP请求.
-func (c *Client) PostStreamRequest(ctx context.Context) (*http.Response, error) {
-	if c.BaseURL == "" || c.Path == "" {
-		return nil, fmt.Errorf("请求的API接口地址或路径未设置")
-	}
-	url := fmt.Sprintf("%s%s", c.BaseURL, c.Path)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(c.Body))
-	if err != nil {
-		return nil, fmt.Errorf("创建请求体错误: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	req.Header.Set("cache-control", "no-cache")
-	req.Header.Set("Content-Type", "application/json")
-	return c.handleRequest(req)
+func (c *Client) PostStreamRequest(ctx context.Context, request *Request) (*http.Response, error) {
+	return c.handleRequest(ctx, http.MethodPost, request, true)
 }
 
-// PostRequest 构造一个post方式的HTTP请求.
-func (c *Client) FormRequest(ctx context.Context) (*http.Response, error) {
-	if c.BaseURL == "" || c.Path == "" {
-		return nil, fmt.Errorf("请求的API接口地址或路径未设置")
-	}
-	url := fmt.Sprintf("%s%s", c.BaseURL, c.Path)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(c.Body))
-	if err != nil {
-		return nil, fmt.Errorf("创建请求体错误: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	req.Header.Set("Content-Type", c.ContentType)
-	return c.handleRequest(req)
+// FormRequest 构造一个post方式提交表单的HTTP请求.
+func (c *Client) FormRequest(ctx context.Context, request *Request) (*http.Response, error) {
+	return c.handleRequest(ctx, http.MethodPost, request, false)
 }
 
-//PostStreamRequest 构造流式响应的post方式HTTP请求.
-
-func (c *Client) FormStreamRequest(ctx context.Context) (*http.Response, error) {
-	if c.BaseURL == "" || c.Path == "" {
-		return nil, fmt.Errorf("请求的API接口地址或路径未设置")
-	}
-	url := fmt.Sprintf("%s%s", c.BaseURL, c.Path)
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(c.Body))
-	if err != nil {
-		return nil, fmt.Errorf("创建请求体错误: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	req.Header.Set("cache-control", "no-cache")
-	req.Header.Set("Content-Type", c.ContentType)
-	return c.handleRequest(req)
+// FormStreamRequest 构造流式响应的post方式提交表单的HTTP请求.
+func (c *Client) FormStreamRequest(ctx context.Context, request *Request) (*http.Response, error) {
+	return c.handleRequest(ctx, http.MethodPost, request, true)
 }
 
 // GetRequest 构造一个get方式的HTTP请求.
-func (c *Client) GetRequest(ctx context.Context) (*http.Response, error) {
-	if c.BaseURL == "" || c.Path == "" {
+func (c *Client) GetRequest(ctx context.Context, request *Request) (*http.Response, error) {
+	return c.handleRequest(ctx, http.MethodGet, request, true)
+}
+
+// newHTTPRequest 根据客户端配置和本次请求数据构造HTTP请求.
+func (c *Client) newHTTPRequest(ctx context.Context, method string, request *Request, stream bool) (*http.Request, error) {
+	reqPath := request.Path
+	if c.BaseURL == "" || reqPath == "" {
 		return nil, fmt.Errorf("请求的API接口地址或路径未设置")
 	}
-	url := fmt.Sprintf("%s%s", c.BaseURL, c.Path)
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, bytes.NewReader(c.Body))
+	if request.Method != "" {
+		method = request.Method
+	}
+	url := fmt.Sprintf("%s%s", c.BaseURL, reqPath)
+	req, err := http.NewRequestWithContext(ctx, method, url, request.BodyReader())
 	if err != nil {
 		return nil, fmt.Errorf("创建请求体错误: %w", err)
 	}
-	req.Header.Set("Authorization", "Bearer "+c.AuthToken)
-	req.Header.Set("cache-control", "no-cache")
-	req.Header.Set("Content-Type", "application/json")
-	return c.handleRequest(req)
-}
-
-// handleRequest使用提供的HTTP客户端发送HTTP请求.
-// 如果没有提供客户端，则使用默认的HTTP客户端.
-func (c *Client) handleRequest(req *http.Request) (*http.Response, error) {
-	client := c.HTTPClient
-	if client == nil {
-		client = http.DefaultClient
+	for key, values := range request.Header {
+		for _, value := range values {
+			req.Header.Add(key, value)
+		}
 	}
-	resp, err := client.Do(req)
+	authorization, err := c.authorization()
 	if err != nil {
-		return nil, fmt.Errorf("正在发送一个错误的请求: %w", err)
+		return nil, fmt.Errorf("生成鉴权信息错误: %w", err)
 	}
-
-	return resp, nil
+	req.Header.Set("Authorization", authorization)
+	if stream {
+		req.Header.Set("cache-control", "no-cache")
+	}
+	contentType := request.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	req.Header.Set("Content-Type", contentType)
+	return req, nil
 }
 
-// HandleAPIError 通过解析响应主体来处理API错误.
-func HandleAPIError(body []byte) error {
-	responseBody := string(body)
-	if len(responseBody) == 0 {
-		return fmt.Errorf("解析响应JSON失败：响应正文为空")
-	}
-	if strings.HasPrefix(responseBody, "<!DOCTYPE html>") {
-		return fmt.Errorf("意外的HTML响应（模型可能不存在）。这可能是一些外部服务器如何返回错误的html响应的问题。确保您调用的路径或模型正确")
+// handleRequest使用提供的HTTP客户端发送HTTP请求.
+// 如果没有提供客户端，则使用默认的HTTP客户端；配置了中间件时，请求会依次经过各中间件.
+// 配置了重试策略时，每次尝试都会根据 Request 重新构造请求体，直到成功、不可重试或上下文取消.
+func (c *Client) handleRequest(ctx context.Context, method string, request *Request, stream bool) (*http.Response, error) {
+	client := c.doer()
+	if request == nil {
+		request = &Request{}
 	}
-	if strings.Contains(responseBody, "{\"error\"") {
-		return fmt.Errorf("无法解析响应JSON: %s", responseBody)
+	policy := c.RetryPolicy
+	attempts := policy.attempts()
+	for attempt := 1; ; attempt++ {
+		req, err := c.newHTTPRequest(ctx, method, request, stream)
+		if err != nil {
+			return nil, err
+		}
+		release := func() {}
+		if c.Limiter != nil {
+			if release, err = c.Limiter.acquire(ctx, c.limiterKey(request.Model)); err != nil {
+				return nil, fmt.Errorf("等待限流时请求被取消: %w", err)
+			}
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			release()
+			if attempt >= attempts || ctx.Err() != nil {
+				return nil, NewTransportError(err)
+			}
+			if err := sleepContext(ctx, policy.delay(attempt, nil)); err != nil {
+				return nil, NewTransportError(err)
+			}
+			continue
+		}
+		resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+		if attempt >= attempts || !policy.retryableResponse(resp) {
+			return resp, nil
+		}
+		delay := policy.delay(attempt, resp)
+		_ = resp.Body.Close()
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, fmt.Errorf("等待重试时请求被取消: %w", err)
+		}
 	}
-	return fmt.Errorf("无法解析响应JSON：JSON输入意外结束. %s", responseBody)
 }
@@ -0,0 +1,144 @@
+package bigModel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 请求失败后的重试策略.
+// 重试只发生在收到响应正文之前：网络错误或可重试的错误状态码，因此流式请求一旦开始接收数据就不会再重试.
+type RetryPolicy struct {
+	MaxAttempts          int           // 最大尝试次数（包含第一次请求），小于等于1表示不重试
+	BaseDelay            time.Duration // 第一次重试前的等待时间，之后按指数增长
+	MaxDelay             time.Duration // 单次等待时间的上限，0表示不限制
+	Jitter               float64       // 随机抖动比例，取值范围 [0,1]，实际等待时间在 delay*(1-Jitter) 到 delay 之间
+	RetryableStatusCodes []int         // 可重试的HTTP状态码
+	RetryableAPICodes    []int         // 可重试的业务错误码 APIError.APICode
+}
+
+// DefaultRetryPolicy 返回默认的重试策略：最多3次尝试，对429和5xx以及限流类业务错误码进行重试.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    10 * time.Second,
+		Jitter:      0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableAPICodes: []int{1302, 1303, 1305}, // 并发数过高、频率过高、触发流量限制
+	}
+}
+
+// WithRetryPolicy 为API客户端设置重试策略.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) error {
+		if policy.Jitter < 0 || policy.Jitter > 1 {
+			return errors.New("retry jitter must be between 0 and 1")
+		}
+		if policy.BaseDelay < 0 || policy.MaxDelay < 0 {
+			return errors.New("retry delay must be a positive duration")
+		}
+		c.RetryPolicy = &policy
+		return nil
+	}
+}
+
+// attempts 返回最大尝试次数.
+func (p *RetryPolicy) attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// retryableStatus 判断响应状态码是否可以重试.
+func (p *RetryPolicy) retryableStatus(statusCode int) bool {
+	return slices.Contains(p.RetryableStatusCodes, statusCode)
+}
+
+// retryableResponse 判断错误响应是否可以重试，并把已读取的响应正文放回 resp.Body 以便调用方继续处理.
+func (p *RetryPolicy) retryableResponse(resp *http.Response) bool {
+	if resp.StatusCode < 400 {
+		return false
+	}
+	if p.retryableStatus(resp.StatusCode) {
+		return true
+	}
+	if len(p.RetryableAPICodes) == 0 {
+		return false
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	apiErr := NewAPIError(resp.StatusCode, resp.Header, body)
+	return slices.Contains(p.RetryableAPICodes, apiErr.APICode)
+}
+
+// delay 计算第 attempt 次重试前的等待时间，attempt 从1开始.
+// 如果响应带有 Retry-After，则以其为准.
+func (p *RetryPolicy) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+			return d
+		}
+	}
+	d := p.BaseDelay
+	for i := 1; i < attempt && (p.MaxDelay == 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter > 0 {
+		d -= time.Duration(float64(d) * p.Jitter * rand.Float64())
+	}
+	return d
+}
+
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数和HTTP日期两种格式.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+// sleepContext 等待指定时间，上下文取消时提前返回.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
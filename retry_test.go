@@ -0,0 +1,95 @@
+package bigModel
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyDelay(t *testing.T) {
+	policy := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	header := func(value string) *http.Response {
+		return &http.Response{Header: http.Header{"Retry-After": []string{value}}}
+	}
+	tests := []struct {
+		name    string
+		attempt int
+		resp    *http.Response
+		want    time.Duration
+	}{
+		{name: "first retry", attempt: 1, want: 100 * time.Millisecond},
+		{name: "exponential", attempt: 2, want: 200 * time.Millisecond},
+		{name: "capped", attempt: 5, want: 300 * time.Millisecond},
+		{name: "retry-after seconds", attempt: 1, resp: header("2"), want: 2 * time.Second},
+		{name: "retry-after past date", attempt: 1, resp: header("Mon, 02 Jan 2006 15:04:05 GMT"), want: 0},
+		{name: "invalid retry-after", attempt: 2, resp: header("soon"), want: 200 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.delay(tt.attempt, tt.resp); got != tt.want {
+				t.Fatalf("delay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryReplaysBody(t *testing.T) {
+	tests := []struct {
+		name       string
+		responses  []int // 每次请求返回的状态码
+		body       string
+		wantStatus int
+		wantCalls  int32
+	}{
+		{name: "retry until success", responses: []int{503, 429, 200}, wantStatus: 200, wantCalls: 3},
+		{name: "give up after max attempts", responses: []int{503, 503, 503, 503}, wantStatus: 503, wantCalls: 3},
+		{name: "not retryable status", responses: []int{400, 200}, wantStatus: 400, wantCalls: 1},
+		{name: "retryable api code", responses: []int{400, 200}, body: `{"error":{"code":"1302","message":"并发数过高"}}`, wantStatus: 200, wantCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != `{"model":"glm-4-flash"}` {
+					t.Errorf("attempt %d body = %q", calls+1, body)
+				}
+				status := tt.responses[atomic.AddInt32(&calls, 1)-1]
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(status)
+				if status >= 400 {
+					errBody := tt.body
+					if errBody == "" {
+						errBody = `{"error":{"code":"1000","message":"error"}}`
+					}
+					_, _ = w.Write([]byte(errBody))
+				}
+			}))
+			defer srv.Close()
+			policy := DefaultRetryPolicy()
+			c, err := NewClientWithOptions("id.secret", WithBaseURL(srv.URL+"/"), WithRetryPolicy(policy))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req, err := NewJSONRequest("chat", map[string]string{"model": "glm-4-flash"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := c.PostRequest(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if calls != tt.wantCalls {
+				t.Fatalf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
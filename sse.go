@@ -0,0 +1,181 @@
+package bigModel
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultSSEMaxLineSize 单行事件数据的默认最大长度
+const DefaultSSEMaxLineSize = 1 << 20
+
+// SSEDone 流式响应结束时服务端发送的数据
+const SSEDone = "[DONE]"
+
+// ErrSSELineTooLong 单行数据超过最大长度时返回.
+var ErrSSELineTooLong = errors.New("sse line exceeds max line size")
+
+// SSEEvent Server-Sent Events 的一个事件.
+type SSEEvent struct {
+	ID    string        // 事件 ID，未设置时沿用上一个事件的 ID.
+	Event string        // 事件类型，未设置时为 "message".
+	Data  string        // 事件数据，多行 data 以换行符连接.
+	Retry time.Duration // 服务端建议的重连间隔.
+}
+
+// SSEDecoder 按照 text/event-stream 规范解析流式响应.
+// 支持 CR、LF、CRLF 换行，注释行，event、id、retry 字段以及多行 data.
+type SSEDecoder struct {
+	scanner     *bufio.Scanner
+	lastEventID string
+}
+
+// NewSSEDecoder 创建一个新的解析器，maxLineSize 小于等于0时使用 DefaultSSEMaxLineSize.
+func NewSSEDecoder(r io.Reader, maxLineSize int) *SSEDecoder {
+	if maxLineSize <= 0 {
+		maxLineSize = DefaultSSEMaxLineSize
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, min(maxLineSize, 64*1024)), maxLineSize)
+	scanner.Split(scanSSELines)
+	return &SSEDecoder{scanner: scanner}
+}
+
+// scanSSELines 按 CR、LF 或 CRLF 切分行.
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		// 遇到 CR 时需要确认后面是否紧跟 LF
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if !atEOF {
+			return 0, nil, nil
+		}
+		return i + 1, data[:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+// Next 读取下一个事件，流结束时返回 io.EOF.
+func (d *SSEDecoder) Next() (*SSEEvent, error) {
+	var (
+		event   SSEEvent
+		data    strings.Builder
+		hasData bool
+	)
+	for d.scanner.Scan() {
+		line := d.scanner.Text()
+		if line == "" {
+			if !hasData {
+				// 没有数据的事件不分发
+				event = SSEEvent{}
+				continue
+			}
+			return d.dispatch(&event, data.String()), nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event.Event = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		case "id":
+			if !strings.ContainsRune(value, 0) {
+				d.lastEventID = value
+			}
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil && ms >= 0 {
+				event.Retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+	}
+	if err := d.scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return nil, ErrSSELineTooLong
+		}
+		return nil, err
+	}
+	// 流在空行之前结束时，仍然分发已接收到的数据
+	if hasData {
+		return d.dispatch(&event, data.String()), nil
+	}
+	return nil, io.EOF
+}
+
+// dispatch 补全事件的默认字段.
+func (d *SSEDecoder) dispatch(event *SSEEvent, data string) *SSEEvent {
+	event.Data = data
+	event.ID = d.lastEventID
+	if event.Event == "" {
+		event.Event = "message"
+	}
+	return event
+}
+
+// NextJSON 读取下一个事件并将数据解析为 v.
+// 收到 [DONE] 或流结束时返回 io.EOF；收到错误帧时返回 *APIError；读取中断时返回 ErrStreamInterrupted 类型的错误.
+func (d *SSEDecoder) NextJSON(v any) error {
+	event, err := d.Next()
+	if err == io.EOF {
+		return io.EOF
+	}
+	if err != nil {
+		return NewStreamError(err)
+	}
+	data := strings.TrimSpace(event.Data)
+	if data == SSEDone {
+		return io.EOF
+	}
+	if apiErr := streamErrorFrame(event.Event, []byte(data)); apiErr != nil {
+		return apiErr
+	}
+	if err := json.Unmarshal([]byte(data), v); err != nil {
+		return fmt.Errorf("unmarshal error: %w, raw data: %s", err, data)
+	}
+	return nil
+}
+
+// streamErrorFrame 判断事件是否为流式响应中途返回的错误，是则返回对应的 APIError.
+func streamErrorFrame(eventType string, data []byte) *APIError {
+	var frame struct {
+		Error json.RawMessage `json:"error"`
+	}
+	isError := eventType == "error"
+	if !isError && json.Unmarshal(data, &frame) == nil && len(frame.Error) > 0 && string(frame.Error) != "null" {
+		isError = true
+	}
+	if !isError {
+		return nil
+	}
+	apiErr := NewAPIError(0, nil, data)
+	if apiErr.Kind == nil {
+		apiErr.Kind = ErrServerError
+	}
+	return apiErr
+}
@@ -0,0 +1,138 @@
+package bigModel
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSSEDecoderNext(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []SSEEvent
+	}{
+		{
+			name:  "LF",
+			input: "data: a\n\ndata: b\n\n",
+			want:  []SSEEvent{{Event: "message", Data: "a"}, {Event: "message", Data: "b"}},
+		},
+		{
+			name:  "CRLF",
+			input: "data: a\r\n\r\ndata: b\r\n\r\n",
+			want:  []SSEEvent{{Event: "message", Data: "a"}, {Event: "message", Data: "b"}},
+		},
+		{
+			name:  "CR",
+			input: "data: a\r\rdata: b\r\r",
+			want:  []SSEEvent{{Event: "message", Data: "a"}, {Event: "message", Data: "b"}},
+		},
+		{
+			name:  "multi-line data",
+			input: "data: line1\ndata:line2\ndata\n\n",
+			want:  []SSEEvent{{Event: "message", Data: "line1\nline2\n"}},
+		},
+		{
+			name:  "comments, event, id and retry",
+			input: ": keep-alive\nevent: update\nid: 7\nretry: 1500\ndata: x\n\n",
+			want:  []SSEEvent{{ID: "7", Event: "update", Data: "x", Retry: 1500 * time.Millisecond}},
+		},
+		{
+			name:  "id carries over and empty events are skipped",
+			input: "id: 1\ndata: a\n\nevent: ping\n\ndata: b\n\n",
+			want:  []SSEEvent{{ID: "1", Event: "message", Data: "a"}, {ID: "1", Event: "message", Data: "b"}},
+		},
+		{
+			name:  "unterminated last event",
+			input: "data: a",
+			want:  []SSEEvent{{Event: "message", Data: "a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := NewSSEDecoder(strings.NewReader(tt.input), 0)
+			var got []SSEEvent
+			for {
+				event, err := decoder.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				got = append(got, *event)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d events %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("event %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSSEDecoderLineLimit(t *testing.T) {
+	decoder := NewSSEDecoder(strings.NewReader("data: "+strings.Repeat("x", 100)+"\n\n"), 32)
+	if _, err := decoder.Next(); !errors.Is(err, ErrSSELineTooLong) {
+		t.Fatalf("err = %v, want ErrSSELineTooLong", err)
+	}
+}
+
+func TestSSEDecoderNextJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr func(error) bool
+		wantA   int
+	}{
+		{name: "chunk", input: "data: {\"a\":1}\n\n", wantA: 1},
+		{name: "done", input: "data: [DONE]\n\n", wantErr: func(err error) bool { return errors.Is(err, io.EOF) }},
+		{name: "eof", input: "", wantErr: func(err error) bool { return errors.Is(err, io.EOF) }},
+		{
+			name:  "error frame",
+			input: "data: {\"error\":{\"code\":\"1301\",\"message\":\"敏感内容\"}}\n\n",
+			wantErr: func(err error) bool {
+				var apiErr *APIError
+				return errors.As(err, &apiErr) && apiErr.APICode == 1301
+			},
+		},
+		{
+			name:  "error event type",
+			input: "event: error\ndata: {\"message\":\"boom\"}\n\n",
+			wantErr: func(err error) bool {
+				var apiErr *APIError
+				return errors.As(err, &apiErr)
+			},
+		},
+		{
+			name:    "line too long",
+			input:   "data: " + strings.Repeat("x", DefaultSSEMaxLineSize) + "\n\n",
+			wantErr: func(err error) bool { return errors.Is(err, ErrStreamInterrupted) && errors.Is(err, ErrSSELineTooLong) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v struct {
+				A int `json:"a"`
+			}
+			err := NewSSEDecoder(strings.NewReader(tt.input), 0).NextJSON(&v)
+			if tt.wantErr != nil {
+				if !tt.wantErr(err) {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if v.A != tt.wantA {
+				t.Fatalf("a = %d, want %d", v.A, tt.wantA)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package bigModel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ForwardStream 循环调用 recv 读取流式响应，并把每个响应块交给 onChunk 处理，结束后调用 closeFn 关闭流.
+// 流正常结束时返回 nil；onChunk 返回 false 时停止读取并返回 nil；ctx 取消时关闭流并返回 ctx.Err().
+func ForwardStream[T any](ctx context.Context, recv func() (T, error), closeFn func() error, onChunk func(chunk T) bool) error {
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+			// 关闭响应正文以中断阻塞中的 recv
+			_ = closeFn()
+		case <-finished:
+		}
+	}()
+	defer func() { _ = closeFn() }()
+	for {
+		chunk, err := recv()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !onChunk(chunk) {
+			return nil
+		}
+	}
+}
+
+// StreamEvents 在后台读取流式响应，把每个响应块经 convert 转换后的事件依次发送到返回的通道.
+// 流结束后发送 finish 生成的结束事件并关闭通道，err 为 nil 表示正常结束，ctx 取消时为 ctx.Err().
+// 结束事件总能送达：ctx 取消后调用方可能已经停止读取，此时丢弃尚未读取的事件，把结束事件放入通道缓冲区，
+// 因此调用方取消 ctx 后可以放弃读取，后台协程不会阻塞.
+func StreamEvents[T, E any](ctx context.Context, recv func() (T, error), closeFn func() error, convert func(chunk T) []E, finish func(err error) E) <-chan E {
+	// 缓冲区保证取消后结束事件不需要等待接收方
+	events := make(chan E, 1)
+	go func() {
+		defer close(events)
+		err := ForwardStream(ctx, recv, closeFn, func(chunk T) bool {
+			for _, event := range convert(chunk) {
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		})
+		if err == nil {
+			err = ctx.Err()
+		}
+		terminal := finish(err)
+		select {
+		case events <- terminal:
+			return
+		case <-ctx.Done():
+		}
+		for {
+			select {
+			case events <- terminal:
+				return
+			default:
+			}
+			// 丢弃取消后仍未被读取的事件，为结束事件腾出缓冲区
+			select {
+			case <-events:
+			default:
+			}
+		}
+	}()
+	return events
+}
+
+// HandleStreamEvents 读取 start 返回的事件直到结束，并对每个事件调用 handler.
+// eventErr 返回事件携带的错误，不为 nil 时停止读取并返回该错误；handler 返回错误时取消读取并返回该错误；正常结束返回 nil.
+func HandleStreamEvents[E any](ctx context.Context, start func(ctx context.Context) <-chan E, eventErr func(event E) error, handler func(event E) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for event := range start(ctx) {
+		if err := eventErr(event); err != nil {
+			return err
+		}
+		if err := handler(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ErrStreamIdleTimeout 流式响应在空闲超时时间内没有收到新数据.
+var ErrStreamIdleTimeout = errors.New("流式响应空闲超时")
+
+// WithStreamIdleTimeout 设置流式响应的空闲超时时间，两次收到数据的间隔超过该时间时中断读取，0表示不限制.
+func WithStreamIdleTimeout(d time.Duration) Option {
+	return func(c *Client) error {
+		if d < 0 {
+			return fmt.Errorf("stream idle timeout must be a positive duration")
+		}
+		c.StreamIdleTimeout = d
+		return nil
+	}
+}
+
+// StreamBody 包装流式响应正文，在 ctx 取消或空闲超时时立即关闭正文，使阻塞中的读取尽快返回.
+type StreamBody struct {
+	body  io.ReadCloser
+	idle  time.Duration
+	timer *time.Timer
+	stop  func() bool
+	mu    sync.Mutex
+	cause error
+}
+
+// NewStreamBody 创建带空闲超时和取消的流式响应正文，idle 小于等于0时不限制空闲时间.
+func NewStreamBody(ctx context.Context, body io.ReadCloser, idle time.Duration) *StreamBody {
+	b := &StreamBody{body: body, idle: idle}
+	if idle > 0 {
+		b.timer = time.AfterFunc(idle, func() { b.abort(ErrStreamIdleTimeout) })
+		b.timer.Stop()
+	}
+	b.stop = context.AfterFunc(ctx, func() { b.abort(ctx.Err()) })
+	return b
+}
+
+// abort 记录中断原因并关闭正文.
+func (b *StreamBody) abort(cause error) {
+	b.mu.Lock()
+	if b.cause == nil {
+		b.cause = cause
+	}
+	b.mu.Unlock()
+	_ = b.body.Close()
+}
+
+// Read 读取响应正文，等待数据的时间超过空闲超时时返回 ErrStreamIdleTimeout，ctx 取消时返回 ctx.Err().
+func (b *StreamBody) Read(p []byte) (int, error) {
+	if b.timer != nil {
+		b.timer.Reset(b.idle)
+	}
+	n, err := b.body.Read(p)
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	if err != nil && err != io.EOF {
+		b.mu.Lock()
+		if b.cause != nil {
+			err = b.cause
+		}
+		b.mu.Unlock()
+	}
+	return n, err
+}
+
+// Close 关闭响应正文并停止空闲计时.
+func (b *StreamBody) Close() error {
+	b.stop()
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	return b.body.Close()
+}
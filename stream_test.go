@@ -0,0 +1,79 @@
+package bigModel
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingBody 阻塞读取直到被关闭，并记录 Close 调用次数.
+type countingBody struct {
+	closed chan struct{}
+	closes int32
+}
+
+func newCountingBody() *countingBody {
+	return &countingBody{closed: make(chan struct{})}
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	<-b.closed
+	return 0, errors.New("use of closed connection")
+}
+
+func (b *countingBody) Close() error {
+	if atomic.AddInt32(&b.closes, 1) == 1 {
+		close(b.closed)
+	}
+	return nil
+}
+
+func TestStreamBodyAbort(t *testing.T) {
+	tests := []struct {
+		name   string
+		idle   time.Duration
+		cancel bool
+		want   error
+	}{
+		{name: "idle timeout", idle: 50 * time.Millisecond, want: ErrStreamIdleTimeout},
+		{name: "ctx cancelled", cancel: true, want: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			b := NewStreamBody(ctx, newCountingBody(), tt.idle)
+			defer func() { _ = b.Close() }()
+			if tt.cancel {
+				time.AfterFunc(50*time.Millisecond, cancel)
+			}
+			if _, err := b.Read(make([]byte, 1)); !errors.Is(err, tt.want) {
+				t.Fatalf("Read() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamBodyCloseReleasesContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	body := newCountingBody()
+	b := NewStreamBody(ctx, body, time.Minute)
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if b.stop() {
+		t.Fatal("Close() did not stop the context callback")
+	}
+	cancel()
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(&body.closes); got != 1 {
+		t.Fatalf("body closed %d times, want 1", got)
+	}
+	if _, err := b.Read(make([]byte, 1)); err == nil || errors.Is(err, context.Canceled) || errors.Is(err, io.EOF) {
+		t.Fatalf("Read() after Close error = %v, want the underlying close error", err)
+	}
+}
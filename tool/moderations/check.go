@@ -0,0 +1,203 @@
+package moderations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"io"
+	"net/http"
+)
+
+// ModerationsPath 内容安全接口的默认路径
+const ModerationsPath = "paas/v4/moderations"
+
+// DefaultModel 内容安全接口默认使用的模型
+const DefaultModel = "moderation"
+
+// 审核内容的类型
+const (
+	InputTypeText  = "text"      // 文本
+	InputTypeImage = "image_url" // 图片
+	InputTypeAudio = "audio_url" // 音频
+	InputTypeVideo = "video_url" // 视频
+)
+
+// Decision 审核结论.
+type Decision string
+
+const (
+	// DecisionPass 内容安全，可以直接使用
+	DecisionPass Decision = "PASS"
+	// DecisionReview 内容存在风险，建议人工复核
+	DecisionReview Decision = "REVIEW"
+	// DecisionReject 内容违规，应当拦截
+	DecisionReject Decision = "REJECT"
+)
+
+// severity 返回审核结论的严重程度，用于比较.
+func (d Decision) severity() int {
+	switch d {
+	case DecisionReject:
+		return 2
+	case DecisionReview:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// MediaUrl 图片、音频、视频的地址.
+type MediaUrl struct {
+	Url string `json:"url"` // 媒体的 URL 地址.
+}
+
+// Input 需要审核的内容，Type 决定哪个字段有效.
+type Input struct {
+	Type     string    `json:"type"`                // 内容类型，见 InputType 常量 (required).
+	Text     string    `json:"text,omitempty"`      // 文本内容，Type 为 text 时有效.
+	ImageUrl *MediaUrl `json:"image_url,omitempty"` // 图片，Type 为 image_url 时有效.
+	AudioUrl *MediaUrl `json:"audio_url,omitempty"` // 音频，Type 为 audio_url 时有效.
+	VideoUrl *MediaUrl `json:"video_url,omitempty"` // 视频，Type 为 video_url 时有效.
+}
+
+// TextInput 创建文本审核内容.
+func TextInput(text string) Input {
+	return Input{Type: InputTypeText, Text: text}
+}
+
+// ImageInput 创建图片审核内容.
+func ImageInput(url string) Input {
+	return Input{Type: InputTypeImage, ImageUrl: &MediaUrl{Url: url}}
+}
+
+// AudioInput 创建音频审核内容.
+func AudioInput(url string) Input {
+	return Input{Type: InputTypeAudio, AudioUrl: &MediaUrl{Url: url}}
+}
+
+// VideoInput 创建视频审核内容.
+func VideoInput(url string) Input {
+	return Input{Type: InputTypeVideo, VideoUrl: &MediaUrl{Url: url}}
+}
+
+// ModerationRequest 内容安全审核请求.
+type ModerationRequest struct {
+	Model string `json:"model"` // 模型代码，默认为 moderation (required).
+	Input Input  `json:"input"` // 需要审核的内容 (required).
+}
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+func (r *ModerationRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("model", r.Model)
+	switch r.Input.Type {
+	case InputTypeText:
+		v.CheckRequired("input.text", r.Input.Text)
+	case InputTypeImage:
+		checkMediaUrl(v, "input.image_url.url", r.Input.ImageUrl)
+	case InputTypeAudio:
+		checkMediaUrl(v, "input.audio_url.url", r.Input.AudioUrl)
+	case InputTypeVideo:
+		checkMediaUrl(v, "input.video_url.url", r.Input.VideoUrl)
+	default:
+		v.Add("input.type", "取值应为 text、image_url、audio_url、video_url 之一，当前为%q", r.Input.Type)
+	}
+	return v.Err()
+}
+
+// checkMediaUrl 校验媒体地址不能为空.
+func checkMediaUrl(v *bigModel.ValidationError, field string, media *MediaUrl) {
+	if media == nil {
+		v.Add(field, "不能为空")
+		return
+	}
+	v.CheckRequired(field, media.Url)
+}
+
+// ResultItem 单项审核结果.
+type ResultItem struct {
+	ContentType string   `json:"content_type"` // 审核内容的类型.
+	RiskLevel   Decision `json:"risk_level"`   // 风险等级：PASS、REVIEW、REJECT.
+	RiskType    []string `json:"risk_type"`    // 命中的风险类别，例如 porn、violence、politics.
+}
+
+// ModerationResponse 内容安全审核接口的响应.
+type ModerationResponse struct {
+	Id         string       `json:"id"`          // 任务 ID.
+	Created    int64        `json:"created"`     // 请求创建时间，Unix 时间戳（秒）.
+	RequestId  string       `json:"request_id"`  // 请求 ID.
+	ResultList []ResultItem `json:"result_list"` // 审核结果列表.
+}
+
+// CheckResult 内容安全审核的结论.
+type CheckResult struct {
+	Decision   Decision            // 综合结论，取全部审核结果中最严重的风险等级.
+	Categories map[string]Decision // 每个风险类别的最高风险等级.
+	Response   *ModerationResponse // 接口的原始响应.
+}
+
+// Check 审核文本、图片、音频或视频内容，返回每个风险类别的风险等级和综合结论.
+func Check(ctx context.Context, c *bigModel.Client, input Input) (*CheckResult, error) {
+	request := &ModerationRequest{Model: DefaultModel, Input: input}
+	if err := c.Validate(request); err != nil {
+		return nil, err
+	}
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error getting timeout context: %w", err)
+	}
+	defer tcancel()
+	req, err := bigModel.NewJSONRequest(ModerationsPath, request)
+	if err != nil {
+		return nil, err
+	}
+	req.Model = request.Model
+	resp, err := c.PostRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, bigModel.HandleError(resp)
+	}
+	respData, err := HandleModerationResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	return NewCheckResult(respData), nil
+}
+
+// NewCheckResult 汇总审核接口的响应，得到每个风险类别的风险等级和综合结论.
+func NewCheckResult(resp *ModerationResponse) *CheckResult {
+	result := &CheckResult{Decision: DecisionPass, Categories: make(map[string]Decision), Response: resp}
+	if resp == nil {
+		return result
+	}
+	for _, item := range resp.ResultList {
+		if item.RiskLevel.severity() > result.Decision.severity() {
+			result.Decision = item.RiskLevel
+		}
+		for _, category := range item.RiskType {
+			if current, ok := result.Categories[category]; !ok || item.RiskLevel.severity() > current.severity() {
+				result.Categories[category] = item.RiskLevel
+			}
+		}
+	}
+	return result
+}
+
+// HandleModerationResponse 解析来自内容安全接口的响应.
+func HandleModerationResponse(resp *http.Response) (*ModerationResponse, error) {
+	body, err := io.ReadAll(resp.Body) //一次性全部读取响应.
+	if err != nil {
+		return nil, fmt.Errorf("无法读取响应正文: %w", err)
+	}
+	var parsedResponse ModerationResponse
+	if err := json.Unmarshal(body, &parsedResponse); err != nil {
+		return nil, bigModel.HandleAPIError(body)
+	}
+	if parsedResponse.Id == "" && parsedResponse.RequestId == "" {
+		return nil, fmt.Errorf("无效响应: 缺少响应ID")
+	}
+	return &parsedResponse, nil
+}
@@ -0,0 +1,30 @@
+package moderations
+
+// FinishReasonSensitive 推理因输入或输出内容违规被终止时的 finish_reason.
+const FinishReasonSensitive = "sensitive"
+
+// Verdict 由对话响应中的 content_filter 和 finish_reason 得出的内容安全结论.
+type Verdict struct {
+	Decision Decision // 结论：被终止或存在 level 0 时为 REJECT，存在其他等级时为 REVIEW，否则为 PASS.
+	Level    int      // 最严重的等级，0表示最严重，3表示轻微；没有命中时为 -1.
+	Role     string   // 最严重等级的生效环节：assistant、user、history.
+	Blocked  bool     // 推理是否因内容违规被终止，即 finish_reason 为 sensitive.
+}
+
+// NewVerdict 根据响应中的 content_filter 和 finish_reason 得出内容安全结论.
+func NewVerdict(filters []ContentFilter, finishReason string) Verdict {
+	verdict := Verdict{Decision: DecisionPass, Level: -1, Blocked: finishReason == FinishReasonSensitive}
+	for _, filter := range filters {
+		if verdict.Level < 0 || filter.Level < verdict.Level {
+			verdict.Level = filter.Level
+			verdict.Role = filter.Role
+		}
+	}
+	switch {
+	case verdict.Blocked || verdict.Level == 0:
+		verdict.Decision = DecisionReject
+	case verdict.Level > 0:
+		verdict.Decision = DecisionReview
+	}
+	return verdict
+}
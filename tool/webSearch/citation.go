@@ -0,0 +1,37 @@
+package webSearch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// citationPattern 匹配回复内容中的角标，例如 [ref_1] 或 【ref_1】.
+var citationPattern = regexp.MustCompile(`[\[【](ref_(\d+))[\]】]`)
+
+// RenderCitations 将回复内容中的角标替换为 Markdown 链接，例如 [ref_1] 替换为 [1](https://...).
+// 没有对应搜索结果或结果没有链接的角标保持不变.
+func RenderCitations(content string, results []WebSearch) string {
+	return RenderCitationsFunc(content, results, func(number string, result WebSearch) string {
+		return fmt.Sprintf("[%s](%s)", number, result.Link)
+	})
+}
+
+// RenderCitationsFunc 使用 render 替换回复内容中的角标，number 为角标中的序号.
+// 没有对应搜索结果或结果没有链接的角标保持不变.
+func RenderCitationsFunc(content string, results []WebSearch, render func(number string, result WebSearch) string) string {
+	refers := make(map[string]WebSearch, len(results))
+	for _, result := range results {
+		if result.Refer != "" && result.Link != "" {
+			refers[strings.TrimSpace(result.Refer)] = result
+		}
+	}
+	return citationPattern.ReplaceAllStringFunc(content, func(marker string) string {
+		match := citationPattern.FindStringSubmatch(marker)
+		result, ok := refers[match[1]]
+		if !ok {
+			return marker
+		}
+		return render(match[2], result)
+	})
+}
@@ -0,0 +1,24 @@
+package webSearch
+
+// 搜索引擎
+const (
+	EngineStd      = "search_std"       // 智谱基础版搜索引擎
+	EnginePro      = "search_pro"       // 智谱高阶版搜索引擎
+	EngineProSogou = "search_pro_sogou" // 搜狗
+	EngineProQuark = "search_pro_quark" // 夸克搜索
+)
+
+// 搜索结果的时间范围
+const (
+	RecencyOneDay   = "oneDay"   // 一天内
+	RecencyOneWeek  = "oneWeek"  // 一周内
+	RecencyOneMonth = "oneMonth" // 一个月内
+	RecencyOneYear  = "oneYear"  // 一年内
+	RecencyNoLimit  = "noLimit"  // 不限，默认值
+)
+
+// Engines 支持的搜索引擎.
+var Engines = []string{EngineStd, EnginePro, EngineProSogou, EngineProQuark}
+
+// RecencyFilters 支持的搜索结果时间范围.
+var RecencyFilters = []string{RecencyOneDay, RecencyOneWeek, RecencyOneMonth, RecencyOneYear, RecencyNoLimit}
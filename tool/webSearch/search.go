@@ -0,0 +1,108 @@
+package webSearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dfpopp/bigModel"
+	"io"
+	"net/http"
+)
+
+// SearchPath 网络搜索接口的默认路径
+const SearchPath = "paas/v4/web_search"
+
+// RecommendedSearchQueryLength 建议的搜索内容最大字符数，超出时搜索效果可能下降，但不会被校验拒绝.
+const RecommendedSearchQueryLength = 70
+
+// SearchRequest 网络搜索请求.
+type SearchRequest struct {
+	SearchQuery         string `json:"search_query"`                    // 搜索内容，建议不超过70个字符 (required).
+	SearchEngine        string `json:"search_engine"`                   // 搜索引擎：search_std、search_pro、search_pro_sogou、search_pro_quark (required).
+	SearchIntent        bool   `json:"search_intent,omitempty"`         // 是否先识别搜索意图，识别为无需搜索时不返回结果.
+	Count               int    `json:"count,omitempty"`                 // 返回结果的条数，范围1-50，默认10.
+	SearchDomainFilter  string `json:"search_domain_filter,omitempty"`  // 只返回指定域名的结果，例如 www.example.com.
+	SearchRecencyFilter string `json:"search_recency_filter,omitempty"` // 搜索结果的时间范围：oneDay、oneWeek、oneMonth、oneYear、noLimit.
+	ContentSize         string `json:"content_size,omitempty"`          // 网页摘要的字数：medium（摘要）、high（更多上下文）.
+	RequestId           string `json:"request_id,omitempty"`            // 请求唯一标识符，若未提供平台将自动生成.
+	UserId              string `json:"user_id,omitempty"`               // 终端用户的唯一标识符。ID长度要求：最少6个字符，最多128个字符.
+}
+
+// SearchIntent 搜索意图识别结果.
+type SearchIntent struct {
+	Query    string `json:"query"`    // 原始搜索内容.
+	Intent   string `json:"intent"`   // 识别的意图类型，SEARCH_ALL 表示需要搜索，SEARCH_NONE 表示无需搜索.
+	Keywords string `json:"keywords"` // 改写后的搜索关键词.
+}
+
+// SearchResponse 网络搜索结果.
+type SearchResponse struct {
+	Id           string         `json:"id"`            // 任务 ID.
+	Created      int64          `json:"created"`       // 请求创建时间，Unix 时间戳（秒）.
+	RequestId    string         `json:"request_id"`    // 请求 ID.
+	SearchIntent []SearchIntent `json:"search_intent"` // 搜索意图识别结果.
+	SearchResult []WebSearch    `json:"search_result"` // 搜索结果，按相关性从高到低排列.
+}
+
+// Validate 校验请求参数，返回包含全部不合法字段的 *bigModel.ValidationError.
+func (r *SearchRequest) Validate() error {
+	v := &bigModel.ValidationError{}
+	v.CheckRequired("search_query", r.SearchQuery)
+	v.CheckRequired("search_engine", r.SearchEngine)
+	v.CheckOneOf("search_engine", r.SearchEngine, Engines...)
+	v.CheckOneOf("search_recency_filter", r.SearchRecencyFilter, RecencyFilters...)
+	v.CheckOneOf("content_size", r.ContentSize, "medium", "high")
+	if r.Count < 0 || r.Count > 50 {
+		v.Add("count", "取值范围为1-50，当前为%d", r.Count)
+	}
+	v.CheckUserId("user_id", r.UserId)
+	return v.Err()
+}
+
+// Search 调用网络搜索接口，返回按相关性排序的搜索结果.
+func Search(ctx context.Context, c *bigModel.Client, request *SearchRequest) (*SearchResponse, error) {
+	if request == nil {
+		return nil, fmt.Errorf("请求不能为空")
+	}
+	if err := c.Validate(request); err != nil {
+		return nil, err
+	}
+	ctx, tcancel, err := bigModel.GetTimeoutContext(ctx, c.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("error getting timeout context: %w", err)
+	}
+	defer tcancel()
+	req, err := bigModel.NewJSONRequest(SearchPath, request)
+	if err != nil {
+		return nil, err
+	}
+	req.Model = request.SearchEngine
+	resp, err := c.PostRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("请求失败: %w", err)
+	}
+	if resp.StatusCode >= 400 {
+		return nil, bigModel.HandleError(resp)
+	}
+	respData, err := HandleSearchResponse(resp)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding response: %w", err)
+	}
+	return respData, nil
+}
+
+// HandleSearchResponse 解析来自网络搜索接口的响应.
+func HandleSearchResponse(resp *http.Response) (*SearchResponse, error) {
+	body, err := io.ReadAll(resp.Body) //一次性全部读取响应.
+	if err != nil {
+		return nil, fmt.Errorf("无法读取响应正文: %w", err)
+	}
+	var parsedResponse SearchResponse
+	if err := json.Unmarshal(body, &parsedResponse); err != nil {
+		return nil, bigModel.HandleAPIError(body)
+	}
+	if parsedResponse.Id == "" && parsedResponse.RequestId == "" {
+		return nil, fmt.Errorf("无效响应: 缺少响应ID")
+	}
+	return &parsedResponse, nil
+}
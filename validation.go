@@ -0,0 +1,124 @@
+package bigModel
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator 可以在发送前校验参数的请求.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError 单个字段的校验错误.
+type FieldError struct {
+	Field   string // 字段名称，与 JSON 字段名一致.
+	Message string // 错误原因.
+}
+
+// Error 实现 error 接口.
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError 请求参数校验失败，包含全部不合法的字段，errors.Is(err, ErrInvalidRequest) 为 true.
+type ValidationError struct {
+	Fields []FieldError // 不合法的字段.
+}
+
+// Error 实现 error 接口.
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Error())
+	}
+	return "请求参数校验失败: " + strings.Join(messages, "; ")
+}
+
+// Is 使 errors.Is(err, ErrInvalidRequest) 成立.
+func (e *ValidationError) Is(target error) bool {
+	return target == ErrInvalidRequest
+}
+
+// Add 记录一个字段错误.
+func (e *ValidationError) Add(field, format string, args ...any) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// Err 没有字段错误时返回 nil，否则返回自身.
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// CheckRequired 校验字符串字段不能为空.
+func (e *ValidationError) CheckRequired(field, value string) {
+	if strings.TrimSpace(value) == "" {
+		e.Add(field, "不能为空")
+	}
+}
+
+// CheckUserId 校验终端用户ID，设置时长度需在6到128个字符之间.
+func (e *ValidationError) CheckUserId(field, userId string) {
+	if userId == "" {
+		return
+	}
+	if n := utf8.RuneCountInString(userId); n < 6 || n > 128 {
+		e.Add(field, "长度需在6到128个字符之间，当前为%d", n)
+	}
+}
+
+// CheckUnitInterval 校验取值在 [0.0, 1.0] 之间且最多两位小数，例如 temperature 和 top_p.
+func (e *ValidationError) CheckUnitInterval(field string, value float64) {
+	// 请求中的 float32 转换为 float64 后按 float32 精度展示
+	text := strconv.FormatFloat(value, 'g', -1, 32)
+	if value < 0 || value > 1 {
+		e.Add(field, "取值范围为 [0.0, 1.0]，当前为%s", text)
+		return
+	}
+	// float32 转换为 float64 时会有微小误差，按1e-4容忍
+	if scaled := value * 100; math.Abs(scaled-math.Round(scaled)) > 1e-4 {
+		e.Add(field, "最多两位小数，当前为%s", text)
+	}
+}
+
+// CheckMaxLength 校验字符串的字符数不超过 max.
+func (e *ValidationError) CheckMaxLength(field, value string, max int) {
+	if n := utf8.RuneCountInString(value); n > max {
+		e.Add(field, "长度不能超过%d个字符，当前为%d", max, n)
+	}
+}
+
+// CheckOneOf 校验字符串字段设置时为允许的取值之一.
+func (e *ValidationError) CheckOneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.Add(field, "取值应为 %s 之一，当前为%q", strings.Join(allowed, "、"), value)
+}
+
+// WithSkipValidation 发送请求前不再自动校验请求参数.
+func WithSkipValidation() Option {
+	return func(c *Client) error {
+		c.SkipValidation = true
+		return nil
+	}
+}
+
+// Validate 在发送请求前校验请求参数，Client.SkipValidation 为 true 时跳过.
+func (c *Client) Validate(request Validator) error {
+	if c.SkipValidation || request == nil {
+		return nil
+	}
+	return request.Validate()
+}